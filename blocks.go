@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// Slack Block Kit limits.
+const (
+	maxBlocksPerMessage = 50
+	maxSectionText      = 3000
+	maxSectionFields    = 10
+	maxFieldText        = 2000
+	maxHeaderText       = 150
+)
+
+// BlockMessage is one Slack message of a Block Kit report.
+type BlockMessage struct {
+	Fallback string // notification and accessibility text
+	Blocks   []slack.Block
+}
+
+// FormatBlocks renders the report as Block Kit messages, each within Slack's
+// block count and text length limits.
+func FormatBlocks(r *Report) []BlockMessage {
+	title := reportTitle(r)
+	l := r.loc()
+	var blocks []slack.Block
+
+	blocks = append(blocks, slack.NewHeaderBlock(plainText(truncate(":zombie: "+title, maxHeaderText))))
+
+	if len(r.RoyalZombies)+len(r.OtherZombies) == 0 {
+		blocks = append(blocks, mrkdwnSection(l.T("no_zombies")))
+	} else {
+		blocks = append(blocks, zombieFieldBlocks(":crown: *"+l.T("royal_members")+"*", r.RoyalZombies, r)...)
+		blocks = append(blocks, zombieFieldBlocks(":busts_in_silhouette: *"+l.T("other_members")+"*", r.OtherZombies, r)...)
+	}
+
+	if len(r.Excused) > 0 {
+		blocks = append(blocks, excusedBlocks(r)...)
+	}
+
+	if len(r.BelowExpectation) > 0 && !r.SummaryOnly {
+		blocks = append(blocks, slack.NewDividerBlock(), mrkdwnSection(":hourglass_flowing_sand: *"+l.T("below_expectation")+"*"))
+		for _, a := range r.BelowExpectation {
+			blocks = append(blocks, activeMemberBlocks(a, r)...)
+		}
+	}
+
+	if len(r.Active) > 0 && !r.SummaryOnly {
+		blocks = append(blocks, slack.NewDividerBlock(), mrkdwnSection(":white_check_mark: *"+l.T("active_members")+"*"))
+		for _, a := range r.Active {
+			blocks = append(blocks, activeMemberBlocks(a, r)...)
+		}
+	}
+
+	blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, reportFooter(r), false, false)))
+
+	// Pack blocks into messages without exceeding the per-message block limit
+	var messages []BlockMessage
+	for i := 0; i < len(blocks); i += maxBlocksPerMessage {
+		end := min(i+maxBlocksPerMessage, len(blocks))
+		fallback := title
+		if i > 0 {
+			fallback = fmt.Sprintf("%s (%s)", title, fmt.Sprintf(l.T("part"), i/maxBlocksPerMessage+1))
+		}
+		messages = append(messages, BlockMessage{Fallback: fallback, Blocks: blocks[i:end]})
+	}
+	return messages
+}
+
+// zombieFieldBlocks renders a zombie group as sections of up to ten name fields,
+// or one section with excuse buttons per member when the report is interactive.
+func zombieFieldBlocks(header string, members []MemberReport, r *Report) []slack.Block {
+	if len(members) == 0 {
+		return nil
+	}
+	if r.Interactive {
+		return zombieButtonBlocks(header, members, r)
+	}
+	var blocks []slack.Block
+	for i := 0; i < len(members); i += maxSectionFields {
+		var fields []*slack.TextBlockObject
+		for _, z := range members[i:min(i+maxSectionFields, len(members))] {
+			fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, truncate(zombieBlockName(z, r), maxFieldText), false, false))
+		}
+		var text *slack.TextBlockObject
+		if i == 0 {
+			text = slack.NewTextBlockObject(slack.MarkdownType, header, false, false)
+		}
+		blocks = append(blocks, slack.NewSectionBlock(text, fields, nil))
+	}
+	return blocks
+}
+
+func zombieBlockName(z MemberReport, r *Report) string {
+	name := zombieLabel(z, r)
+	switch {
+	case r.isChronic(z):
+		return ":skull: " + name
+	case r.History:
+		return ":new: " + name
+	}
+	return name
+}
+
+// zombieButtonBlocks renders each zombie with buttons to excuse them for the
+// day, snooze them for a week, or mark them on leave. Button values carry the
+// member and the report period start, see handleInteraction.
+func zombieButtonBlocks(header string, members []MemberReport, r *Report) []slack.Block {
+	l := r.loc()
+	blocks := []slack.Block{mrkdwnSection(header)}
+	for _, z := range members {
+		value := excuseValue(z.UserID, r)
+		blocks = append(blocks,
+			mrkdwnSection(truncate(zombieBlockName(z, r), maxSectionText)),
+			slack.NewActionBlock("excuse_"+z.UserID,
+				slack.NewButtonBlockElement(actionExcuse+ExcuseToday, value, plainText(l.T("button.today"))),
+				slack.NewButtonBlockElement(actionExcuse+ExcuseSnooze, value, plainText(l.T("button.snooze"))),
+				slack.NewButtonBlockElement(actionExcuse+ExcuseLeave, value, plainText(l.T("button.leave"))),
+			),
+		)
+	}
+	return blocks
+}
+
+// excusedBlocks lists excused members, each with a button ending the excusal
+// when the report is interactive.
+func excusedBlocks(r *Report) []slack.Block {
+	l := r.loc()
+	header := ":palm_tree: *" + l.T("excused") + "*"
+	if !r.Interactive {
+		var names []string
+		for _, e := range r.Excused {
+			names = append(names, fmt.Sprintf("@%s (%s)", e.DisplayName, excusalLabel(e, l)))
+		}
+		return []slack.Block{slack.NewDividerBlock(), mrkdwnSection(truncate(header+"\n"+strings.Join(names, " | "), maxSectionText))}
+	}
+	blocks := []slack.Block{slack.NewDividerBlock(), mrkdwnSection(header)}
+	for _, e := range r.Excused {
+		section := mrkdwnSection(truncate(fmt.Sprintf("@%s (%s)", e.DisplayName, excusalLabel(e, l)), maxSectionText))
+		button := slack.NewButtonBlockElement(actionExcuse+excuseClear, excuseValue(e.UserID, r), plainText(l.T("button.clear")))
+		section.Accessory = slack.NewAccessory(button)
+		blocks = append(blocks, section)
+	}
+	return blocks
+}
+
+func excuseValue(userID string, r *Report) string {
+	return fmt.Sprintf("%s %d", userID, r.From.Unix())
+}
+
+// activeMemberBlocks renders one member as a compact section with a button
+// opening their first PR, splitting long link lists across sections.
+func activeMemberBlocks(a ActiveMember, r *Report) []slack.Block {
+	text := strings.TrimRight(formatActiveMember(a, r), "\n")
+	var accessory *slack.Accessory
+	if url := firstPRURL(a); url != "" {
+		button := slack.NewButtonBlockElement("open_pr_"+a.UserID, a.UserID, plainText(r.loc().T("open_pr"))).WithURL(url)
+		accessory = slack.NewAccessory(button)
+	}
+	var blocks []slack.Block
+	for i, part := range splitSafe(text, maxSectionText) {
+		section := slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, strings.TrimRight(part, "\n"), false, false), nil, nil)
+		if i == 0 {
+			section.Accessory = accessory
+		}
+		blocks = append(blocks, section)
+	}
+	return blocks
+}
+
+func firstPRURL(a ActiveMember) string {
+	if len(a.GitHubPRs) > 0 {
+		return a.GitHubPRs[0].URL
+	}
+	if len(a.Messages) > 0 {
+		return "https://" + normalizePRURL(a.Messages[0].PRURL)
+	}
+	return ""
+}
+
+func mrkdwnSection(text string) *slack.SectionBlock {
+	return slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)
+}
+
+func plainText(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.PlainTextType, text, true, false)
+}
+
+// truncate shortens s to at most maxLen characters, marking the cut with an ellipsis.
+func truncate(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen-1]) + "…"
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+)
+
+func blocksReport() *Report {
+	from := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+	return &Report{Mode: "daily", Source: "both", From: from, To: from.AddDate(0, 0, 1)}
+}
+
+// checkBlockLimits fails the test if a message breaks one of Slack's Block Kit limits.
+func checkBlockLimits(t *testing.T, messages []BlockMessage) {
+	t.Helper()
+	for i, m := range messages {
+		if len(m.Blocks) > maxBlocksPerMessage {
+			t.Errorf("message %d has %d blocks", i, len(m.Blocks))
+		}
+		for _, b := range m.Blocks {
+			switch b := b.(type) {
+			case *slack.HeaderBlock:
+				if n := utf8.RuneCountInString(b.Text.Text); n > maxHeaderText {
+					t.Errorf("message %d: header of %d characters", i, n)
+				}
+			case *slack.SectionBlock:
+				if b.Text != nil && utf8.RuneCountInString(b.Text.Text) > maxSectionText {
+					t.Errorf("message %d: section of %d characters", i, utf8.RuneCountInString(b.Text.Text))
+				}
+				if len(b.Fields) > maxSectionFields {
+					t.Errorf("message %d: section with %d fields", i, len(b.Fields))
+				}
+				for _, f := range b.Fields {
+					if utf8.RuneCountInString(f.Text) > maxFieldText {
+						t.Errorf("message %d: field of %d characters", i, utf8.RuneCountInString(f.Text))
+					}
+				}
+			}
+		}
+	}
+}
+
+func blockTexts(messages []BlockMessage) string {
+	var b strings.Builder
+	for _, m := range messages {
+		for _, block := range m.Blocks {
+			switch block := block.(type) {
+			case *slack.HeaderBlock:
+				b.WriteString(block.Text.Text + "\n")
+			case *slack.SectionBlock:
+				if block.Text != nil {
+					b.WriteString(block.Text.Text + "\n")
+				}
+				for _, f := range block.Fields {
+					b.WriteString(f.Text + "\n")
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+func TestFormatBlocks(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(r *Report)
+		wantMessages int
+		want, absent []string
+	}{
+		{
+			name:         "no zombies",
+			setup:        func(r *Report) {},
+			wantMessages: 1,
+			want:         []string{":zombie: Zombie Report (Daily", "No zombies detected"},
+		},
+		{
+			name: "zombie fields in sections of ten",
+			setup: func(r *Report) {
+				for i := range 25 {
+					r.OtherZombies = append(r.OtherZombies, MemberReport{UserID: fmt.Sprintf("U%d", i), DisplayName: fmt.Sprintf("z%d", i)})
+				}
+				r.RoyalZombies = []MemberReport{{UserID: "UK", DisplayName: "king"}}
+			},
+			wantMessages: 1,
+			want:         []string{"Royal Members", "@king", "Other Members", "@z0", "@z24"},
+		},
+		{
+			name: "long member lists split into messages",
+			setup: func(r *Report) {
+				for i := range 120 {
+					r.Active = append(r.Active, ActiveMember{
+						UserID: fmt.Sprintf("U%d", i), DisplayName: fmt.Sprintf("member%d", i), Count: 1, Required: 1,
+						Messages: []MessageLink{{ChannelID: "C1", Timestamp: "1715600000.000100", PRURL: "https://github.com/org/repo/pull/1"}},
+					})
+				}
+			},
+			wantMessages: 3,
+			want:         []string{"Active Members", "@member0", "@member119"},
+		},
+		{
+			name: "long names are truncated",
+			setup: func(r *Report) {
+				r.OtherZombies = []MemberReport{{UserID: "U1", DisplayName: strings.Repeat("ж", 2100)}}
+				r.Active = []ActiveMember{{UserID: "U2", DisplayName: strings.Repeat("a", 3100), Count: 1, Required: 1}}
+			},
+			wantMessages: 1,
+		},
+		{
+			name: "summary leaves out active members",
+			setup: func(r *Report) {
+				r.SummaryOnly = true
+				r.OtherZombies = []MemberReport{{UserID: "U1", DisplayName: "bob"}}
+				r.BelowExpectation = []ActiveMember{{UserID: "U2", DisplayName: "carol", Count: 1, Required: 2}}
+				r.Active = []ActiveMember{{UserID: "U3", DisplayName: "dave", Count: 1, Required: 1}}
+			},
+			wantMessages: 1,
+			want:         []string{"@bob"},
+			absent:       []string{"@carol", "@dave", "Active Members"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := blocksReport()
+			tt.setup(r)
+			messages := FormatBlocks(r)
+			if len(messages) != tt.wantMessages {
+				t.Fatalf("got %d messages, want %d", len(messages), tt.wantMessages)
+			}
+			checkBlockLimits(t, messages)
+			for i, m := range messages {
+				if i > 0 && !strings.HasSuffix(m.Fallback, fmt.Sprintf("(part %d)", i+1)) {
+					t.Errorf("message %d fallback = %q", i, m.Fallback)
+				}
+			}
+			if _, ok := messages[len(messages)-1].Blocks[len(messages[len(messages)-1].Blocks)-1].(*slack.ContextBlock); !ok {
+				t.Errorf("last block is not the footer")
+			}
+			text := blockTexts(messages)
+			for _, s := range tt.want {
+				if !strings.Contains(text, s) {
+					t.Errorf("blocks lack %q", s)
+				}
+			}
+			for _, s := range tt.absent {
+				if strings.Contains(text, s) {
+					t.Errorf("blocks contain %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatBlocksInteractive(t *testing.T) {
+	r := blocksReport()
+	r.Interactive = true
+	r.OtherZombies = []MemberReport{{UserID: "U1", DisplayName: "bob"}}
+	r.Excused = []ExcusedMember{{UserID: "U2", DisplayName: "erin", Reason: ExcuseLeave}}
+
+	var actions []string
+	for _, m := range FormatBlocks(r) {
+		for _, b := range m.Blocks {
+			switch b := b.(type) {
+			case *slack.ActionBlock:
+				for _, e := range b.Elements.ElementSet {
+					if btn, ok := e.(*slack.ButtonBlockElement); ok {
+						actions = append(actions, btn.ActionID+" "+btn.Value)
+					}
+				}
+			case *slack.SectionBlock:
+				if b.Accessory != nil && b.Accessory.ButtonElement != nil {
+					actions = append(actions, b.Accessory.ButtonElement.ActionID+" "+b.Accessory.ButtonElement.Value)
+				}
+			}
+		}
+	}
+	value := fmt.Sprintf(" %d", r.From.Unix())
+	want := []string{
+		actionExcuse + ExcuseToday + " U1" + value,
+		actionExcuse + ExcuseSnooze + " U1" + value,
+		actionExcuse + ExcuseLeave + " U1" + value,
+		actionExcuse + excuseClear + " U2" + value,
+	}
+	if strings.Join(actions, "\n") != strings.Join(want, "\n") {
+		t.Errorf("buttons = %q, want %q", actions, want)
+	}
+}
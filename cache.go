@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketMessages = []byte("messages")
+	bucketChannels = []byte("channels")
+)
+
+// MessageCache keeps scanned channel messages in the state store so later runs
+// only fetch what is new. The trailing revalidate window before a channel's
+// high-water mark is refetched every run to pick up edits and deletions.
+type MessageCache struct {
+	store      *Store
+	revalidate time.Duration
+}
+
+func NewMessageCache(store *Store, revalidate time.Duration) *MessageCache {
+	return &MessageCache{store: store, revalidate: revalidate}
+}
+
+// channelRange is the contiguous span of a channel's history held in the cache.
+type channelRange struct {
+	Oldest time.Time `json:"oldest"`
+	Latest time.Time `json:"latest"`
+}
+
+type cachedMessage struct {
+	User string `json:"user"`
+	Text string `json:"text"`
+}
+
+// FetchMessages returns the channel's messages in [from, to), fetching from
+// Slack only the part not already cached or due for revalidation.
+func (c *MessageCache) FetchMessages(client *SlackClient, channelID string, from, to time.Time) ([]slack.Message, error) {
+	var rng channelRange
+	ok, err := c.store.get(bucketChannels, channelID, &rng)
+	if err != nil {
+		return nil, fmt.Errorf("reading cache: %w", err)
+	}
+
+	fetchFrom := from
+	if ok && !from.Before(rng.Oldest) && !from.After(rng.Latest) {
+		if revalidateFrom := rng.Latest.Add(-c.revalidate); revalidateFrom.After(from) {
+			fetchFrom = revalidateFrom
+		}
+	}
+
+	if fetchFrom.Before(to) {
+		fresh, err := client.FetchMessages(channelID, fetchFrom, to)
+		if err != nil {
+			return nil, err
+		}
+		contiguous := ok && !fetchFrom.After(rng.Latest) && !to.Before(rng.Oldest)
+		if err := c.replace(channelID, fetchFrom, to, fresh, contiguous, rng); err != nil {
+			return nil, fmt.Errorf("writing cache: %w", err)
+		}
+	}
+
+	var msgs []slack.Message
+	err = c.store.scan(bucketMessages, messageKey(channelID, tsString(from)), messageKey(channelID, tsString(to)),
+		func(k, v []byte) error {
+			var cm cachedMessage
+			if err := json.Unmarshal(v, &cm); err != nil {
+				return err
+			}
+			ts := string(k[len(channelID)+1:])
+			msgs = append(msgs, slack.Message{Msg: slack.Msg{User: cm.User, Text: cm.Text, Timestamp: ts}})
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("reading cache: %w", err)
+	}
+	return msgs, nil
+}
+
+// replace swaps the cached messages in [from, to) for fresh ones, so messages
+// deleted in Slack disappear. A range not touching the cached one resets the
+// channel's cache, keeping the cached span contiguous.
+func (c *MessageCache) replace(channelID string, from, to time.Time, fresh []slack.Message, contiguous bool, rng channelRange) error {
+	return c.store.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketMessages)
+		// Slack's oldest bound is exclusive, so a message exactly at from was not refetched
+		start, end := []byte(messageKey(channelID, tsString(from))+"\x00"), []byte(messageKey(channelID, tsString(to)))
+		if !contiguous {
+			start, end = []byte(channelID+"|"), []byte(channelID+"}")
+			rng = channelRange{Oldest: from, Latest: to}
+		}
+		var stale [][]byte
+		cur := b.Cursor()
+		for k, _ := cur.Seek(start); k != nil && bytes.Compare(k, end) < 0; k, _ = cur.Next() {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		for _, m := range fresh {
+			data, err := json.Marshal(cachedMessage{User: m.User, Text: m.Text})
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(messageKey(channelID, m.Timestamp)), data); err != nil {
+				return err
+			}
+		}
+
+		if from.Before(rng.Oldest) {
+			rng.Oldest = from
+		}
+		if to.After(rng.Latest) {
+			rng.Latest = to
+		}
+		data, err := json.Marshal(rng)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(bucketChannels).Put([]byte(channelID), data)
+	})
+}
+
+func messageKey(channelID, ts string) string {
+	return channelID + "|" + ts
+}
+
+// tsString formats t like a Slack message timestamp so keys sort by time.
+func tsString(t time.Time) string {
+	return fmt.Sprintf("%010d.000000", t.Unix())
+}
+
+// LastPRs returns when each of the given users last posted a PR link before
+// the given time in the cached history of any channel. Each channel is read
+// backwards from before, and only until every user has been found.
+func (s *Store) LastPRs(userIDs []string, before time.Time) (map[string]time.Time, error) {
+	last := make(map[string]time.Time)
+	if len(userIDs) == 0 {
+		return last, nil
+	}
+	wanted := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		msgs := tx.Bucket(bucketMessages).Cursor()
+		return tx.Bucket(bucketChannels).ForEach(func(channelID, _ []byte) error {
+			prefix := []byte(string(channelID) + "|")
+			seen := make(map[string]bool)
+			k, v := msgs.Seek([]byte(messageKey(string(channelID), tsString(before))))
+			if k == nil {
+				k, v = msgs.Last()
+			} else {
+				k, v = msgs.Prev()
+			}
+			for ; k != nil && bytes.HasPrefix(k, prefix) && len(seen) < len(wanted); k, v = msgs.Prev() {
+				var cm cachedMessage
+				if err := json.Unmarshal(v, &cm); err != nil {
+					return err
+				}
+				if !wanted[cm.User] || seen[cm.User] || !githubPR.MatchString(cm.Text) {
+					continue
+				}
+				seen[cm.User] = true
+				if t := (MessageLink{Timestamp: string(k[len(prefix):])}).Time(); t.After(last[cm.User]) {
+					last[cm.User] = t
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading cache: %w", err)
+	}
+	return last, nil
+}
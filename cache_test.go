@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// openTestStore opens a state store in a temporary directory, closed when the
+// test ends.
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := OpenStore(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func testMessage(user string, at time.Time, text string) slack.Message {
+	return slack.Message{Msg: slack.Msg{User: user, Text: text, Timestamp: fmt.Sprintf("%d.000100", at.Unix())}}
+}
+
+func TestStoreLastPRs(t *testing.T) {
+	store := openTestStore(t)
+	cache := NewMessageCache(store, 0)
+	day := func(n int) time.Time { return time.Date(2024, 5, n, 12, 0, 0, 0, time.UTC) }
+	pr := "https://github.com/org/repo/pull/1"
+
+	seed := map[string][]slack.Message{
+		"C1": {
+			testMessage("U1", day(1), pr),
+			testMessage("U1", day(3), "no link"),
+			testMessage("U2", day(2), pr),
+			testMessage("U1", day(10), pr), // inside the period
+		},
+		"C2": {
+			testMessage("U1", day(4), pr),
+			testMessage("U3", day(5), pr),
+		},
+	}
+	for id, msgs := range seed {
+		if err := cache.replace(id, day(1).Add(-time.Hour), day(11), msgs, false, channelRange{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := store.LastPRs([]string{"U1", "U2", "U4"}, day(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]time.Time{"U1": day(4), "U2": day(2)}
+	if len(got) != len(want) {
+		t.Errorf("LastPRs = %v, want %v", got, want)
+	}
+	for id, w := range want {
+		if !got[id].Equal(w) {
+			t.Errorf("LastPRs[%s] = %s, want %s", id, got[id], w)
+		}
+	}
+
+	if got, err := store.LastPRs(nil, day(8)); err != nil || len(got) != 0 {
+		t.Errorf("LastPRs(nil) = %v, %v, want none", got, err)
+	}
+}
+
+func TestMessageCacheFetchMessages(t *testing.T) {
+	day := func(n int) time.Time { return time.Date(2024, 5, n, 0, 0, 0, 0, time.UTC) }
+	at := func(n, hour int) time.Time { return day(n).Add(time.Duration(hour) * time.Hour) }
+	fetched := func(from, to time.Time) string {
+		return fmt.Sprintf("conversations.history C1 %d-%d", from.Unix(), to.Unix())
+	}
+
+	type run struct {
+		history  []slack.Message // Slack's history from this run on; nil keeps it
+		from, to time.Time
+		fetched  []string // history requests the run makes
+		want     []string // texts returned, in time order
+	}
+	tests := []struct {
+		name       string
+		revalidate time.Duration
+		runs       []run
+	}{
+		{
+			name: "cold fetch",
+			runs: []run{{
+				history: []slack.Message{testMessage("U1", at(2, 9), "a"), testMessage("U1", at(4, 9), "b"), testMessage("U1", at(9, 9), "later")},
+				from:    day(1), to: day(5),
+				fetched: []string{fetched(day(1), day(5))},
+				want:    []string{"a", "b"},
+			}},
+		},
+		{
+			name: "extends past the high-water mark",
+			runs: []run{
+				{
+					history: []slack.Message{testMessage("U1", at(2, 9), "a"), testMessage("U1", at(4, 9), "b")},
+					from:    day(1), to: day(5),
+					fetched: []string{fetched(day(1), day(5))},
+					want:    []string{"a", "b"},
+				},
+				{
+					history: []slack.Message{testMessage("U1", at(2, 9), "a"), testMessage("U1", at(4, 9), "b"), testMessage("U1", at(6, 9), "c")},
+					from:    day(1), to: day(8),
+					fetched: []string{fetched(day(5), day(8))},
+					want:    []string{"a", "b", "c"},
+				},
+				{
+					from: day(1), to: day(8),
+					want: []string{"a", "b", "c"},
+				},
+			},
+		},
+		{
+			name: "request before the cached range",
+			runs: []run{
+				{
+					history: []slack.Message{testMessage("U1", at(2, 9), "a"), testMessage("U1", at(4, 9), "b")},
+					from:    day(3), to: day(5),
+					fetched: []string{fetched(day(3), day(5))},
+					want:    []string{"b"},
+				},
+				{
+					from: day(1), to: day(5),
+					fetched: []string{fetched(day(1), day(5))},
+					want:    []string{"a", "b"},
+				},
+				{
+					from: day(2), to: day(4),
+					want: []string{"a"},
+				},
+			},
+		},
+		{
+			name:       "revalidation replaces edited and deleted messages",
+			revalidate: 24 * time.Hour,
+			runs: []run{
+				{
+					history: []slack.Message{testMessage("U1", at(2, 9), "a"), testMessage("U1", at(4, 9), "b"), testMessage("U2", at(4, 18), "c")},
+					from:    day(1), to: day(5),
+					fetched: []string{fetched(day(1), day(5))},
+					want:    []string{"a", "b", "c"},
+				},
+				{
+					// edits before the revalidate window are not seen
+					history: []slack.Message{testMessage("U1", at(2, 9), "a edited"), testMessage("U1", at(4, 9), "b edited")},
+					from:    day(1), to: day(5),
+					fetched: []string{fetched(day(4), day(5))},
+					want:    []string{"a", "b edited"},
+				},
+			},
+		},
+		{
+			name: "a gap resets the channel",
+			runs: []run{
+				{
+					history: []slack.Message{testMessage("U1", at(2, 9), "a"), testMessage("U1", at(6, 9), "c")},
+					from:    day(1), to: day(3),
+					fetched: []string{fetched(day(1), day(3))},
+					want:    []string{"a"},
+				},
+				{
+					from: day(5), to: day(8),
+					fetched: []string{fetched(day(5), day(8))},
+					want:    []string{"c"},
+				},
+				{
+					from: day(1), to: day(8),
+					fetched: []string{fetched(day(1), day(8))},
+					want:    []string{"a", "c"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, client := newFakeSlack(t)
+			cache := NewMessageCache(openTestStore(t), tt.revalidate)
+			for i, r := range tt.runs {
+				if r.history != nil {
+					fake.setHistory("C1", r.history...)
+				}
+				msgs, err := cache.FetchMessages(client, "C1", r.from, r.to)
+				if err != nil {
+					t.Fatalf("run %d: %v", i, err)
+				}
+				var texts []string
+				for _, m := range msgs {
+					texts = append(texts, m.Text)
+				}
+				if !reflect.DeepEqual(texts, r.want) {
+					t.Errorf("run %d: messages = %q, want %q", i, texts, r.want)
+				}
+				if calls := fake.takeCalls(); !reflect.DeepEqual(calls, r.fetched) {
+					t.Errorf("run %d: Slack calls = %q, want %q", i, calls, r.fetched)
+				}
+			}
+		})
+	}
+}
+
+func TestScanForPRsNoCache(t *testing.T) {
+	from, to := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 8, 0, 0, 0, 0, time.UTC)
+	targets := []scanTarget{{"C1", "prs"}}
+	fake, client := newFakeSlack(t)
+	cache := NewMessageCache(openTestStore(t), 0)
+
+	fake.setHistory("C1", testMessage("U1", from.Add(time.Hour), "https://github.com/org/repo/pull/1"))
+	if prs, _ := scanForPRs(client, cache, nil, targets, from, to); len(prs["U1"]) != 1 {
+		t.Fatalf("cached scan found %v, want one PR by U1", prs)
+	}
+
+	// --no-cache sees Slack as it is now and leaves the cache alone
+	fake.setHistory("C1", testMessage("U2", from.Add(2*time.Hour), "https://github.com/org/repo/pull/2"))
+	prs, scanned := scanForPRs(client, nil, nil, targets, from, to)
+	if scanned != 1 || len(prs["U1"]) != 0 || len(prs["U2"]) != 1 {
+		t.Errorf("uncached scan of %d channels found %v, want only U2's PR", scanned, prs)
+	}
+	fake.takeCalls()
+	if prs, _ := scanForPRs(client, cache, nil, targets, from, to); len(prs["U1"]) != 1 || len(prs["U2"]) != 0 {
+		t.Errorf("cached scan after --no-cache found %v, want the cached PR by U1", prs)
+	}
+	if calls := fake.takeCalls(); len(calls) != 0 {
+		t.Errorf("cached scan called Slack: %q", calls)
+	}
+}
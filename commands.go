@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// CommandsConfig enables the /zombies slash command, button actions and
+// @mentions, over HTTP (serve) or Socket Mode (listen).
+type CommandsConfig struct {
+	SigningSecret   string   `yaml:"signing_secret"`
+	AppToken        string   `yaml:"app_token"`        // xapp- token for Socket Mode
+	AuthorizedUsers []string `yaml:"authorized_users"` // user IDs allowed to request reports and use buttons
+}
+
+// authorized reports whether userID may request reports and use buttons.
+// Only IDs are compared: handles and display names can be changed by their
+// owners.
+func (c *CommandsConfig) authorized(userID string) bool {
+	return slices.Contains(c.AuthorizedUsers, userID)
+}
+
+const commandUsage = "Usage: `/zombies [daily|weekly|deep-scan|monthly|sprint|previous-week] [days=N] [slack|github|both] [#channel] [public]`"
+
+// maxResponses is how many messages Slack accepts on one response_url.
+const maxResponses = 5
+
+var (
+	channelMention = regexp.MustCompile(`^<#([A-Z0-9]+)(?:\|([^>]*))?>$`)
+	channelID      = regexp.MustCompile(`^[CG][A-Z0-9]{6,}$`)
+	daysArg        = regexp.MustCompile(`^(?:days=)?(\d+)d?$`)
+	userIDPattern  = regexp.MustCompile(`^[UW][A-Z0-9]{6,}$`)
+)
+
+// commandArgs is a parsed /zombies command.
+type commandArgs struct {
+	Mode, Source string
+	Days         int
+	Channel      *Channel // nil scans the configured channels
+	Public       bool     // post the report in the channel instead of only to the requester
+	Help         bool
+}
+
+// parseCommand parses the text after /zombies. Arguments may come in any
+// order; the mode defaults to daily and the source to both.
+func parseCommand(text string, cfg *Config) (commandArgs, error) {
+	args := commandArgs{Mode: "daily", Source: "both"}
+	for _, tok := range strings.Fields(text) {
+		lower := strings.ToLower(tok)
+		switch {
+		case lower == "help":
+			args.Help = true
+		case lower == "public":
+			args.Public = true
+		case validModes[lower]:
+			args.Mode = lower
+		case validSources[strings.TrimPrefix(lower, "source=")]:
+			args.Source = strings.TrimPrefix(lower, "source=")
+		case daysArg.MatchString(lower):
+			args.Days, _ = strconv.Atoi(daysArg.FindStringSubmatch(lower)[1])
+		case channelMention.MatchString(tok):
+			m := channelMention.FindStringSubmatch(tok)
+			args.Channel = &Channel{ID: m[1], Name: m[2]}
+		case channelID.MatchString(tok):
+			args.Channel = &Channel{ID: tok, Name: tok}
+		case strings.HasPrefix(tok, "#"):
+			ch := cfg.channelByName(strings.TrimPrefix(tok, "#"))
+			if ch == nil {
+				return args, fmt.Errorf("unknown channel %s; pick it from the channel list so Slack sends its ID", tok)
+			}
+			args.Channel = ch
+		default:
+			return args, fmt.Errorf("unknown argument %q", tok)
+		}
+	}
+	return args, nil
+}
+
+// botHandler serves the interactive features: the /zombies slash command,
+// button actions, and @mentions. HTTP and Socket Mode share its handlers.
+type botHandler struct {
+	*reportRunner
+}
+
+// responder sends a reply to whoever made a request.
+type responder func(*slack.WebhookMessage) error
+
+// handleCommand handles a slash command. It returns the reply to
+// acknowledge the command with.
+func (h *botHandler) handleCommand(cmd slack.SlashCommand, respond responder) *slack.WebhookMessage {
+	slog.Info("command", "user", cmd.UserName, "user_id", cmd.UserID, "command", cmd.Command, "text", cmd.Text)
+	return h.handleRequest(cmd.UserID, cmd.Text, respond)
+}
+
+// handleRequest authorizes and parses a report request and starts the
+// report in the background, delivering it through respond. It returns the
+// immediate reply.
+func (h *botHandler) handleRequest(userID, text string, respond responder) *slack.WebhookMessage {
+	if !h.cfg.Commands.authorized(userID) {
+		return ephemeral("Sorry, you are not allowed to request zombie reports.")
+	}
+	args, err := parseCommand(text, h.cfg)
+	if err != nil {
+		return ephemeral(err.Error() + "\n" + commandUsage)
+	}
+	if args.Help {
+		return ephemeral(commandUsage)
+	}
+	go h.runCommand(args, respond)
+	return ephemeral(fmt.Sprintf(":hourglass_flowing_sand: Working on the %s report…", args.Mode))
+}
+
+// runCommand generates the requested report and responds with it, falling
+// back to the summary when the full report needs too many messages.
+func (h *botHandler) runCommand(args commandArgs, respond responder) {
+	report, err := h.detect(args)
+	if err != nil {
+		slog.Error("command: report failed", "err", err)
+		if err := respond(ephemeral("Report failed: " + err.Error())); err != nil {
+			slog.Error("command: responding", "err", err)
+		}
+		return
+	}
+
+	posts := renderSlack(report, "text")
+	if len(posts) > maxResponses {
+		posts = renderSlack(reportView(report, "summary"), "text")
+	}
+	responseType := slack.ResponseTypeEphemeral
+	if args.Public {
+		responseType = slack.ResponseTypeInChannel
+	}
+	for _, p := range posts[:min(len(posts), maxResponses)] {
+		if err := respond(&slack.WebhookMessage{ResponseType: responseType, Text: p.Text}); err != nil {
+			slog.Error("command: responding", "err", err)
+			return
+		}
+	}
+}
+
+// detect runs the report for a command, restricted to one channel's members
+// and messages when a channel was given. Ad hoc reports are not recorded in
+// the history.
+func (h *botHandler) detect(args commandArgs) (*Report, error) {
+	cfg := h.cfg
+	if args.Channel != nil {
+		restricted := *h.cfg
+		restricted.Channels = []Channel{*args.Channel}
+		cfg = &restricted
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	job := ReportJob{Mode: args.Mode, Source: args.Source, Period: PeriodOptions{Days: args.Days}, ByDay: true}
+	report, err := runDetection(cfg, h.client, h.store, job)
+	return report, err
+}
+
+// serveCommand handles slash command requests from Slack.
+func (h *botHandler) serveCommand(w http.ResponseWriter, r *http.Request) {
+	if err := verifySlackRequest(r, h.cfg.Commands.SigningSecret); err != nil {
+		slog.Warn("command: rejected request", "err", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	cmd, err := slack.SlashCommandParse(r)
+	if err != nil {
+		http.Error(w, "invalid command", http.StatusBadRequest)
+		return
+	}
+	reply := h.handleCommand(cmd, responseURL(cmd.ResponseURL))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(reply)
+}
+
+// verifySlackRequest checks the request's signature against the signing
+// secret and leaves the body readable for parsing.
+func verifySlackRequest(r *http.Request, secret string) error {
+	sv, err := slack.NewSecretsVerifier(r.Header, secret)
+	if err != nil {
+		return fmt.Errorf("verifying request: %w", err)
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("reading request: %w", err)
+	}
+	if _, err := sv.Write(body); err != nil {
+		return err
+	}
+	if sv.Ensure() != nil {
+		return fmt.Errorf("verifying request: signature mismatch")
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return nil
+}
+
+// responseURL replies through a slash command's or action's response_url.
+func responseURL(url string) responder {
+	return func(msg *slack.WebhookMessage) error {
+		return slack.PostWebhook(url, msg)
+	}
+}
+
+func ephemeral(text string) *slack.WebhookMessage {
+	return &slack.WebhookMessage{ResponseType: slack.ResponseTypeEphemeral, Text: text}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	cfg := &Config{Channels: []Channel{{ID: "C0PRS01", Name: "prs"}, {ID: "C0ENG01", Name: "Eng"}}}
+	tests := []struct {
+		text    string
+		want    commandArgs
+		wantErr bool
+	}{
+		{text: "", want: commandArgs{Mode: "daily", Source: "both"}},
+		{text: "  ", want: commandArgs{Mode: "daily", Source: "both"}},
+		{text: "weekly", want: commandArgs{Mode: "weekly", Source: "both"}},
+		{text: "DEEP-SCAN github", want: commandArgs{Mode: "deep-scan", Source: "github"}},
+		{text: "source=slack previous-week", want: commandArgs{Mode: "previous-week", Source: "slack"}},
+		{text: "days=3", want: commandArgs{Mode: "daily", Source: "both", Days: 3}},
+		{text: "14d weekly", want: commandArgs{Mode: "weekly", Source: "both", Days: 14}},
+		{text: "5", want: commandArgs{Mode: "daily", Source: "both", Days: 5}},
+		{text: "public help", want: commandArgs{Mode: "daily", Source: "both", Public: true, Help: true}},
+		{text: "<#C0XYZ12|random>", want: commandArgs{Mode: "daily", Source: "both", Channel: &Channel{ID: "C0XYZ12", Name: "random"}}},
+		{text: "<#C0XYZ12>", want: commandArgs{Mode: "daily", Source: "both", Channel: &Channel{ID: "C0XYZ12"}}},
+		{text: "G0PRIV12", want: commandArgs{Mode: "daily", Source: "both", Channel: &Channel{ID: "G0PRIV12", Name: "G0PRIV12"}}},
+		{text: "#eng monthly", want: commandArgs{Mode: "monthly", Source: "both", Channel: &Channel{ID: "C0ENG01", Name: "Eng"}}},
+		{text: "#unknown", wantErr: true},
+		{text: "yearly", wantErr: true},
+		{text: "days=", wantErr: true},
+		{text: "source=email", wantErr: true},
+		{text: "weekly extra", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseCommand(tt.text, cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCommand(%q) error = %v, want error %v", tt.text, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCommand(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCommandsAuthorized(t *testing.T) {
+	c := CommandsConfig{AuthorizedUsers: []string{"U0LEAD01"}}
+	for userID, want := range map[string]bool{"U0LEAD01": true, "U0OTHER1": false, "u0lead01": false, "": false} {
+		if got := c.authorized(userID); got != want {
+			t.Errorf("authorized(%q) = %t, want %t", userID, got, want)
+		}
+	}
+}
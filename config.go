@@ -3,7 +3,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"text/template"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,17 +15,42 @@ type Channel struct {
 	Name string `yaml:"name"`
 }
 
+// Sprint describes a fixed-length sprint cadence anchored at a start date.
+type Sprint struct {
+	Start      string `yaml:"start"`
+	LengthDays int    `yaml:"length_days"`
+}
+
 type Config struct {
-	SlackToken      string            `yaml:"slack_token"`
-	UserToken       string            `yaml:"user_token"`
-	Workspace       string            `yaml:"workspace"`
-	GitHubToken     string            `yaml:"github_token"`
-	GitHubOrg       string            `yaml:"github_org"`
-	GitHubUsers     map[string]string `yaml:"github_users"`
-	Channels        []Channel         `yaml:"channels"`
-	ReportRecipient string            `yaml:"report_recipient"`
-	Whitelist       []string          `yaml:"whitelist"`
-	RoyalMembers    []string          `yaml:"royal_members"`
+	SlackToken           string            `yaml:"slack_token"`
+	UserToken            string            `yaml:"user_token"`
+	Workspace            string            `yaml:"workspace"`
+	GitHubToken          string            `yaml:"github_token"`
+	GitHubOrg            string            `yaml:"github_org"`
+	GitHubUsers          map[string]string `yaml:"github_users"`
+	Channels             []Channel         `yaml:"channels"`
+	ReportRecipient      string            `yaml:"report_recipient"`
+	Whitelist            []string          `yaml:"whitelist"`
+	RoyalMembers         []string          `yaml:"royal_members"`
+	Sprint               Sprint            `yaml:"sprint"`
+	Expectations         []Expectation     `yaml:"expectations"`
+	StatePath            string            `yaml:"state_path"`
+	ChronicStreak        int               `yaml:"chronic_streak"`
+	CacheRevalidateHours int               `yaml:"cache_revalidate_hours"`
+	Delivery             []Destination     `yaml:"delivery"`
+	SMTP                 SMTPConfig        `yaml:"smtp"`
+	ReportTemplate       string            `yaml:"report_template"`
+	Locale               string            `yaml:"locale"`
+	Schedules            []Schedule        `yaml:"schedules"`
+	Serve                ServeConfig       `yaml:"serve"`
+	Commands             CommandsConfig    `yaml:"commands"`
+	Nudge                NudgeConfig       `yaml:"nudge"`
+	Escalations          []EscalationRule  `yaml:"escalations"`
+	Managers             map[string]string `yaml:"managers"`      // member ID or display name to manager user ID
+	ManagerField         string            `yaml:"manager_field"` // profile field holding a member's manager
+
+	reportTemplate *template.Template
+	locale         *Locale
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -43,13 +70,107 @@ func LoadConfig(path string) (*Config, error) {
 	if len(cfg.Channels) == 0 {
 		return nil, fmt.Errorf("at least one channel is required")
 	}
-	if cfg.ReportRecipient == "" {
-		return nil, fmt.Errorf("report_recipient is required")
+	if cfg.ReportRecipient == "" && len(cfg.Delivery) == 0 {
+		return nil, fmt.Errorf("report_recipient or delivery is required")
+	}
+	if err := cfg.validateDelivery(path, "delivery", cfg.Delivery); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for i := range cfg.Schedules {
+		s := &cfg.Schedules[i]
+		if err := s.validate(); err != nil {
+			return nil, fmt.Errorf("schedules[%d]: %w", i, err)
+		}
+		if names[s.Name] {
+			return nil, fmt.Errorf("schedules[%d]: duplicate name %q", i, s.Name)
+		}
+		names[s.Name] = true
+		if err := cfg.validateDelivery(path, fmt.Sprintf("schedules[%d].delivery", i), s.Delivery); err != nil {
+			return nil, err
+		}
+	}
+	if (cfg.Commands.SigningSecret != "" || cfg.Commands.AppToken != "") && len(cfg.Commands.AuthorizedUsers) == 0 {
+		return nil, fmt.Errorf("commands.authorized_users is required with a signing secret or app token")
+	}
+	for i, u := range cfg.Commands.AuthorizedUsers {
+		if !userIDPattern.MatchString(u) {
+			return nil, fmt.Errorf("commands.authorized_users[%d]: %q is not a user ID; look it up with \"users find\"", i, u)
+		}
+	}
+	if err := cfg.Serve.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.ChronicStreak <= 0 {
+		cfg.ChronicStreak = 3
+	}
+	if cfg.SMTP.Port == 0 {
+		cfg.SMTP.Port = 587
+	}
+	if cfg.CacheRevalidateHours <= 0 {
+		cfg.CacheRevalidateHours = 24
+	}
+	if cfg.StatePath == "" {
+		cfg.StatePath = "zombie-state.db"
+	}
+	cfg.StatePath = relativeTo(path, cfg.StatePath)
+	for i := range cfg.Expectations {
+		if err := cfg.Expectations[i].validate(); err != nil {
+			return nil, fmt.Errorf("expectations[%d]: %w", i, err)
+		}
+	}
+	if cfg.locale, err = LookupLocale(cfg.Locale); err != nil {
+		return nil, err
+	}
+	names = make(map[string]bool)
+	for i := range cfg.Escalations {
+		e := &cfg.Escalations[i]
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("escalations[%d]: %w", i, err)
+		}
+		if names[e.Name] {
+			return nil, fmt.Errorf("escalations[%d]: duplicate name %q", i, e.Name)
+		}
+		names[e.Name] = true
+	}
+	if err := cfg.Nudge.validate(&cfg, path); err != nil {
+		return nil, err
+	}
+	if cfg.ReportTemplate != "" {
+		cfg.ReportTemplate = relativeTo(path, cfg.ReportTemplate)
+		if cfg.reportTemplate, err = LoadReportTemplate(cfg.ReportTemplate); err != nil {
+			return nil, err
+		}
 	}
 
 	return &cfg, nil
 }
 
+// validateDelivery checks and completes a list of destinations, resolving
+// template paths against the config file.
+func (c *Config) validateDelivery(path, field string, dests []Destination) error {
+	for i := range dests {
+		if err := dests[i].validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", field, i, err)
+		}
+		if dests[i].Template != "" {
+			dests[i].Template = relativeTo(path, dests[i].Template)
+		}
+		if dests[i].Type == "email" && (c.SMTP.Host == "" || c.SMTP.From == "") {
+			return fmt.Errorf("%s[%d]: email requires smtp.host and smtp.from", field, i)
+		}
+	}
+	return nil
+}
+
+// relativeTo resolves a path from the config file against the file's directory.
+func relativeTo(configPath, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(filepath.Dir(configPath), p)
+}
+
 func (c *Config) IsWhitelisted(userID, displayName string) bool {
 	return c.matchList(c.Whitelist, userID, displayName)
 }
@@ -58,11 +179,38 @@ func (c *Config) IsRoyal(userID, displayName string) bool {
 	return c.matchList(c.RoyalMembers, userID, displayName)
 }
 
+// ExpectationFor returns the first expectation listing the member, or nil.
+func (c *Config) ExpectationFor(userID, displayName string) *Expectation {
+	for i := range c.Expectations {
+		if c.matchList(c.Expectations[i].Members, userID, displayName) {
+			return &c.Expectations[i]
+		}
+	}
+	return nil
+}
+
+// channelByName returns the configured channel with the given name, or nil.
+func (c *Config) channelByName(name string) *Channel {
+	for i := range c.Channels {
+		if strings.EqualFold(c.Channels[i].Name, name) {
+			return &c.Channels[i]
+		}
+	}
+	return nil
+}
+
 func (c *Config) matchList(list []string, userID, displayName string) bool {
+	_, ok := matchEntry(list, userID, displayName)
+	return ok
+}
+
+// matchEntry returns the first entry of list naming the member by user ID or
+// display name.
+func matchEntry(list []string, userID, displayName string) (string, bool) {
 	for _, entry := range list {
 		if entry == userID || strings.EqualFold(entry, displayName) {
-			return true
+			return entry, true
 		}
 	}
-	return false
+	return "", false
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"x * * * *",
+		"@yearly",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	kyiv, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Skip(err)
+	}
+	// Wednesday
+	at := func(day, hour, minute int) time.Time { return time.Date(2024, 5, day, hour, minute, 0, 0, time.UTC) }
+
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", at(15, 10, 30), at(15, 10, 31)},
+		{"0 9 * * 1-5", at(15, 8, 59), at(15, 9, 0)},
+		{"0 9 * * 1-5", at(15, 9, 0), at(16, 9, 0)},
+		{"0 9 * * mon-fri", at(17, 10, 0), at(20, 9, 0)},
+		{"0 9 * * mon", at(15, 10, 0), at(20, 9, 0)},
+		{"0 9 * * 7", at(15, 10, 0), at(19, 9, 0)},
+		{"*/15 * * * *", at(15, 10, 1), at(15, 10, 15)},
+		{"0 8-18/5 * * *", at(15, 13, 30), at(15, 18, 0)},
+		{"30 6 1,15 * *", at(15, 7, 0), time.Date(2024, 6, 1, 6, 30, 0, 0, time.UTC)},
+		{"0 0 29 feb *", at(15, 0, 0), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", at(15, 0, 0), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		// either restricted day field matches
+		{"0 0 1 * fri", at(15, 0, 0), at(17, 0, 0)},
+		{"0 9 * * *", time.Date(2024, 5, 15, 10, 0, 0, 0, kyiv), time.Date(2024, 5, 16, 9, 0, 0, 0, kyiv)},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := c.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%s) = %s, want %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
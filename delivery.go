@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+var (
+	bucketThreads = []byte("threads")
+	bucketPosts   = []byte("posts")
+)
+
+// Destination is one entry of the delivery config section.
+type Destination struct {
+	Type   string   `yaml:"type"`   // dm, channel, group_dm, thread, email, webhook, mattermost, or teams
+	To     []string `yaml:"to"`     // user IDs for dm/group_dm, channel IDs for channel/thread/mattermost, addresses for email
+	Format string   `yaml:"format"` // text or blocks; defaults to --format
+	Detail string   `yaml:"detail"` // full (default) or summary
+	Update bool     `yaml:"update"` // edit the period's earlier report instead of posting again
+
+	// Webhook, Mattermost and Teams destinations
+	URL         string            `yaml:"url"`
+	Token       string            `yaml:"token"` // Mattermost bot token; url is then the server URL
+	Headers     map[string]string `yaml:"headers"`
+	Secret      string            `yaml:"secret"`       // HMAC-SHA256 key for the signature header
+	Template    string            `yaml:"template"`     // text/template file for the body; default is the JSON report
+	ContentType string            `yaml:"content_type"` // webhook body type, default application/json
+	Retries     *int              `yaml:"retries"`      // default 3, 0 for none
+}
+
+// retries returns how often a failed webhook-style post is retried.
+func (d *Destination) retries() int {
+	if d.Retries == nil {
+		return 3
+	}
+	return *d.Retries
+}
+
+func (d *Destination) validate() error {
+	switch d.Type {
+	case "dm", "channel", "group_dm", "thread", "email":
+		if len(d.To) == 0 {
+			return fmt.Errorf("%s destination needs at least one entry in to", d.Type)
+		}
+	case "webhook", "mattermost", "teams":
+		if d.URL == "" {
+			return fmt.Errorf("%s destination needs a url", d.Type)
+		}
+		if d.Type == "mattermost" && d.Token != "" && len(d.To) == 0 {
+			return fmt.Errorf("mattermost destination with a token needs channel IDs in to")
+		}
+		if d.Retries != nil && *d.Retries < 0 {
+			return fmt.Errorf("retries must not be negative")
+		}
+		if d.ContentType == "" {
+			d.ContentType = "application/json"
+		}
+	default:
+		return fmt.Errorf("invalid type %q: must be dm, channel, group_dm, thread, email, webhook, mattermost, or teams", d.Type)
+	}
+	switch d.Format {
+	case "", "text", "blocks":
+	default:
+		return fmt.Errorf("invalid format %q: must be text or blocks", d.Format)
+	}
+	switch d.Detail {
+	case "":
+		d.Detail = "full"
+	case "full", "summary":
+	default:
+		return fmt.Errorf("invalid detail %q: must be full or summary", d.Detail)
+	}
+	return nil
+}
+
+// Sink delivers a report to one configured destination.
+type Sink interface {
+	Name() string
+	Deliver(r *Report) (sent int, err error)
+	Preview(w io.Writer, r *Report) error
+}
+
+// NewSinks builds a sink per destination, or per configured destination when
+// dests is empty. Without a delivery section the report goes as a DM to
+// report_recipient, as it always has.
+func NewSinks(cfg *Config, dests []Destination, client *SlackClient, store *Store, defaultFormat string) ([]Sink, error) {
+	if len(dests) == 0 {
+		dests = cfg.Delivery
+	}
+	if len(dests) == 0 {
+		dests = []Destination{{Type: "dm", To: []string{cfg.ReportRecipient}, Detail: "full"}}
+	}
+	sinks := make([]Sink, 0, len(dests))
+	for _, d := range dests {
+		if d.Format == "" {
+			d.Format = defaultFormat
+		}
+		switch d.Type {
+		case "email":
+			sinks = append(sinks, &EmailSink{dest: d, smtp: cfg.SMTP})
+		case "webhook":
+			ws, err := NewWebhookSink(d)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, ws)
+		case "mattermost":
+			sinks = append(sinks, &MattermostSink{dest: d})
+		case "teams":
+			sinks = append(sinks, &TeamsSink{dest: d})
+		default:
+			sinks = append(sinks, &SlackSink{dest: d, client: client, store: store})
+		}
+	}
+	return sinks, nil
+}
+
+// reportView returns the report as a destination with the given detail level should see it.
+func reportView(r *Report, detail string) *Report {
+	if detail != "summary" {
+		return r
+	}
+	summary := *r
+	summary.SummaryOnly = true
+	return &summary
+}
+
+// SlackSink posts reports to Slack DMs, channels, group DMs, or a daily thread.
+type SlackSink struct {
+	dest   Destination
+	client *SlackClient
+	store  *Store
+}
+
+func (s *SlackSink) Name() string {
+	return fmt.Sprintf("%s %s (%s, %s)", s.dest.Type, strings.Join(s.dest.To, ","), s.dest.Format, s.dest.Detail)
+}
+
+func (s *SlackSink) Deliver(r *Report) (int, error) {
+	posts := renderSlack(reportView(r, s.dest.Detail), s.dest.Format)
+
+	var channels []string
+	if s.dest.Type == "group_dm" {
+		ch, err := s.client.OpenGroupDM(s.dest.To)
+		if err != nil {
+			return 0, err
+		}
+		channels = []string{ch}
+	} else {
+		channels = s.dest.To
+	}
+
+	sent := 0
+	for _, ch := range channels {
+		var threadTS string
+		if s.dest.Type == "thread" {
+			ts, err := s.dailyThread(ch, r.From, r.loc())
+			if err != nil {
+				return sent, err
+			}
+			threadTS = ts
+		}
+		n, err := s.deliverTo(ch, posts, threadTS, runKey(r.From, r.Mode))
+		sent += n
+		if err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// postedMessage identifies a report message Slack accepted, for later updates.
+type postedMessage struct {
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+// deliverTo posts the report to one channel. With update enabled it edits the
+// messages posted earlier for the same period instead, adding or deleting
+// trailing parts when the part count changed, and posts afresh when Slack no
+// longer has them.
+func (s *SlackSink) deliverTo(ch string, posts []SlackPost, threadTS, period string) (int, error) {
+	key := strings.Join([]string{s.dest.Type, ch, s.dest.Format, s.dest.Detail, period}, "|")
+	var prev []postedMessage
+	if s.dest.Update {
+		if _, err := s.store.get(bucketPosts, key, &prev); err != nil {
+			return 0, fmt.Errorf("reading posted messages: %w", err)
+		}
+	}
+
+	if len(prev) > 0 {
+		record, err := s.updatePosts(prev, posts, threadTS)
+		if err == nil {
+			return len(posts), s.savePosts(key, record)
+		}
+		if !errors.Is(err, ErrMessageGone) {
+			return 0, err
+		}
+		slog.Info("earlier report messages are gone, posting afresh", "channel", ch, "period", period)
+		for _, m := range prev {
+			_ = s.client.Delete(m.Channel, m.TS)
+		}
+	}
+
+	var record []postedMessage
+	for i, p := range posts {
+		channel, ts, err := s.client.Post(ch, p, threadTS)
+		if err != nil {
+			return i, err
+		}
+		record = append(record, postedMessage{channel, ts})
+	}
+	if !s.dest.Update {
+		return len(posts), nil
+	}
+	return len(posts), s.savePosts(key, record)
+}
+
+func (s *SlackSink) updatePosts(prev []postedMessage, posts []SlackPost, threadTS string) ([]postedMessage, error) {
+	var record []postedMessage
+	for i, p := range posts {
+		if i < len(prev) {
+			if err := s.client.Update(prev[i].Channel, prev[i].TS, p); err != nil {
+				return nil, err
+			}
+			record = append(record, prev[i])
+			continue
+		}
+		channel, ts, err := s.client.Post(prev[0].Channel, p, threadTS)
+		if err != nil {
+			return nil, err
+		}
+		record = append(record, postedMessage{channel, ts})
+	}
+	for _, m := range prev[min(len(posts), len(prev)):] {
+		if err := s.client.Delete(m.Channel, m.TS); err != nil && !errors.Is(err, ErrMessageGone) {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+func (s *SlackSink) savePosts(key string, record []postedMessage) error {
+	if err := s.store.put(bucketPosts, key, record); err != nil {
+		return fmt.Errorf("saving posted messages: %w", err)
+	}
+	return nil
+}
+
+func (s *SlackSink) Preview(w io.Writer, r *Report) error {
+	for _, p := range renderSlack(reportView(r, s.dest.Detail), s.dest.Format) {
+		if p.Blocks == nil {
+			if _, err := fmt.Fprint(w, p.Text); err != nil {
+				return err
+			}
+			continue
+		}
+		out, err := json.MarshalIndent(map[string]any{"blocks": p.Blocks}, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, string(out)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dailyThread returns the ts of the parent message in channelID for reports
+// whose period starts on day, posting it first, in l's language, if this is
+// the day's first report.
+func (s *SlackSink) dailyThread(channelID string, day time.Time, l *Locale) (string, error) {
+	key := channelID + "|" + day.Format(dateLayout)
+	var ts string
+	if ok, err := s.store.get(bucketThreads, key, &ts); err != nil {
+		return "", fmt.Errorf("reading thread: %w", err)
+	} else if ok {
+		return ts, nil
+	}
+	parent := SlackPost{Text: fmt.Sprintf(l.T("thread.parent"), l.FormatDate(day))}
+	_, ts, err := s.client.Post(channelID, parent, "")
+	if err != nil {
+		return "", err
+	}
+	if err := s.store.put(bucketThreads, key, ts); err != nil {
+		return "", fmt.Errorf("saving thread: %w", err)
+	}
+	return ts, nil
+}
+
+// SlackPost is one rendered Slack message: plain mrkdwn text, or Block Kit
+// blocks with Text as the notification fallback.
+type SlackPost struct {
+	Text   string
+	Blocks []slack.Block
+}
+
+func renderSlack(r *Report, format string) []SlackPost {
+	var posts []SlackPost
+	if format == "blocks" {
+		for _, m := range FormatBlocks(r) {
+			posts = append(posts, SlackPost{Text: m.Fallback, Blocks: m.Blocks})
+		}
+		return posts
+	}
+	for _, m := range FormatReport(r) {
+		posts = append(posts, SlackPost{Text: m})
+	}
+	return posts
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testReport returns a daily report with one zombie in locale l.
+func testReport(l *Locale) *Report {
+	from := time.Date(2024, 5, 13, 0, 0, 0, 0, time.Local)
+	return &Report{
+		Mode: "daily", Source: "slack", From: from, To: from.AddDate(0, 0, 1),
+		OtherZombies: []MemberReport{{UserID: "U1", DisplayName: "bob"}},
+		TotalCount:   1,
+		locale:       l,
+	}
+}
+
+func TestDailyThreadLocale(t *testing.T) {
+	fake, client := newFakeSlack(t)
+	sink := &SlackSink{dest: Destination{Type: "thread", To: []string{"C1"}, Format: "text", Detail: "full"}, client: client, store: openTestStore(t)}
+
+	for range 2 {
+		if _, err := sink.Deliver(testReport(localeDE)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantCalls := []string{
+		"chat.postMessage C1 1700000000.000001",
+		"chat.postMessage C1 1700000000.000002 in 1700000000.000001",
+		"chat.postMessage C1 1700000000.000003 in 1700000000.000001",
+	}
+	if calls := fake.takeCalls(); !slices.Equal(calls, wantCalls) {
+		t.Errorf("calls = %q, want %q", calls, wantCalls)
+	}
+	texts := fake.postedTexts("C1")
+	if len(texts) != 3 {
+		t.Fatalf("posted %d messages, want the parent and two reports: %q", len(texts), texts)
+	}
+	if want := ":zombie: Zombie-Berichte für Mo 13.05.2024"; texts[0] != want {
+		t.Errorf("parent = %q, want %q", texts[0], want)
+	}
+	for _, text := range texts[1:] {
+		if !strings.Contains(text, "Zombie-Bericht") {
+			t.Errorf("report %q is not in German", text)
+		}
+	}
+}
+
+func TestSlackSinkUpdate(t *testing.T) {
+	ts := func(n int) string { return fmt.Sprintf("1700000000.%06d", n) }
+	post := func(n int) string { return "chat.postMessage C1 " + ts(n) }
+	update := func(n int) string { return "chat.update C1 " + ts(n) }
+	del := func(n int) string { return "chat.delete C1 " + ts(n) }
+
+	type delivery struct {
+		gone      []int // messages deleted in Slack before this delivery
+		period    string
+		parts     []string
+		wantCalls []string
+		wantTexts []string // the channel afterwards
+	}
+	tests := []struct {
+		name       string
+		update     bool
+		deliveries []delivery
+	}{
+		{
+			name:   "same part count updates in place",
+			update: true,
+			deliveries: []delivery{
+				{period: "p1", parts: []string{"a", "b"}, wantCalls: []string{post(1), post(2)}, wantTexts: []string{"a", "b"}},
+				{period: "p1", parts: []string{"c", "d"}, wantCalls: []string{update(1), update(2)}, wantTexts: []string{"c", "d"}},
+			},
+		},
+		{
+			name:   "more parts are posted after the updated ones",
+			update: true,
+			deliveries: []delivery{
+				{period: "p1", parts: []string{"a"}, wantCalls: []string{post(1)}, wantTexts: []string{"a"}},
+				{period: "p1", parts: []string{"b", "c", "d"}, wantCalls: []string{update(1), post(2), post(3)}, wantTexts: []string{"b", "c", "d"}},
+				{period: "p1", parts: []string{"e", "f", "g"}, wantCalls: []string{update(1), update(2), update(3)}, wantTexts: []string{"e", "f", "g"}},
+			},
+		},
+		{
+			name:   "fewer parts delete the trailing ones",
+			update: true,
+			deliveries: []delivery{
+				{period: "p1", parts: []string{"a", "b", "c"}, wantCalls: []string{post(1), post(2), post(3)}, wantTexts: []string{"a", "b", "c"}},
+				{period: "p1", parts: []string{"d"}, wantCalls: []string{update(1), del(2), del(3)}, wantTexts: []string{"d"}},
+				{period: "p1", parts: []string{"e"}, wantCalls: []string{update(1)}, wantTexts: []string{"e"}},
+			},
+		},
+		{
+			name:   "a trailing part already gone is not an error",
+			update: true,
+			deliveries: []delivery{
+				{period: "p1", parts: []string{"a", "b"}, wantCalls: []string{post(1), post(2)}, wantTexts: []string{"a", "b"}},
+				{gone: []int{2}, period: "p1", parts: []string{"c"}, wantCalls: []string{update(1), del(2)}, wantTexts: []string{"c"}},
+			},
+		},
+		{
+			name:   "a gone message reposts the whole report",
+			update: true,
+			deliveries: []delivery{
+				{period: "p1", parts: []string{"a", "b"}, wantCalls: []string{post(1), post(2)}, wantTexts: []string{"a", "b"}},
+				{gone: []int{1}, period: "p1", parts: []string{"c", "d"}, wantCalls: []string{update(1), del(1), del(2), post(3), post(4)}, wantTexts: []string{"c", "d"}},
+				{period: "p1", parts: []string{"e", "f"}, wantCalls: []string{update(3), update(4)}, wantTexts: []string{"e", "f"}},
+			},
+		},
+		{
+			name:   "a new period posts afresh",
+			update: true,
+			deliveries: []delivery{
+				{period: "p1", parts: []string{"a"}, wantCalls: []string{post(1)}, wantTexts: []string{"a"}},
+				{period: "p2", parts: []string{"b"}, wantCalls: []string{post(2)}, wantTexts: []string{"a", "b"}},
+			},
+		},
+		{
+			name: "without update every delivery posts",
+			deliveries: []delivery{
+				{period: "p1", parts: []string{"a"}, wantCalls: []string{post(1)}, wantTexts: []string{"a"}},
+				{period: "p1", parts: []string{"b"}, wantCalls: []string{post(2)}, wantTexts: []string{"a", "b"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, client := newFakeSlack(t)
+			sink := &SlackSink{dest: Destination{Type: "channel", To: []string{"C1"}, Format: "text", Detail: "full", Update: tt.update}, client: client, store: openTestStore(t)}
+			for i, d := range tt.deliveries {
+				for _, n := range d.gone {
+					fake.removePosted("C1", ts(n))
+				}
+				var posts []SlackPost
+				for _, p := range d.parts {
+					posts = append(posts, SlackPost{Text: p})
+				}
+				sent, err := sink.deliverTo("C1", posts, "", d.period)
+				if err != nil {
+					t.Fatalf("delivery %d: %v", i, err)
+				}
+				if sent != len(posts) {
+					t.Errorf("delivery %d: sent %d, want %d", i, sent, len(posts))
+				}
+				if calls := fake.takeCalls(); !slices.Equal(calls, d.wantCalls) {
+					t.Errorf("delivery %d: calls = %q, want %q", i, calls, d.wantCalls)
+				}
+				if texts := fake.postedTexts("C1"); !slices.Equal(texts, d.wantTexts) {
+					t.Errorf("delivery %d: channel holds %q, want %q", i, texts, d.wantTexts)
+				}
+			}
+		})
+	}
+}
+
+func TestSlackSinkUpdateThread(t *testing.T) {
+	fake, client := newFakeSlack(t)
+	sink := &SlackSink{dest: Destination{Type: "thread", To: []string{"C1"}, Format: "blocks", Detail: "full", Update: true}, client: client, store: openTestStore(t)}
+
+	r := testReport(localeEN)
+	if _, err := sink.Deliver(r); err != nil {
+		t.Fatal(err)
+	}
+	r.OtherZombies = nil
+	if _, err := sink.Deliver(r); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"chat.postMessage C1 1700000000.000001",
+		"chat.postMessage C1 1700000000.000002 in 1700000000.000001",
+		"chat.update C1 1700000000.000002",
+	}
+	if calls := fake.takeCalls(); !slices.Equal(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
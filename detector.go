@@ -2,11 +2,15 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
+
+	"github.com/slack-go/slack"
 )
 
 var githubPR = regexp.MustCompile(`github\.com/[^/]+/[^/]+/pull/\d+`)
@@ -28,7 +32,20 @@ func (m MessageLink) Time() time.Time {
 	return time.Unix(sec, 0)
 }
 
-type MemberReport struct{ DisplayName string }
+type MemberReport struct {
+	UserID, DisplayName string
+	Streak              int       // consecutive zombie runs including this one, 0 without history
+	StreakFrom          time.Time // period start of the streak's first run
+}
+
+// ExcusedMember is a member who would have been a zombie or below
+// expectation but has an excusal for the period.
+type ExcusedMember struct {
+	UserID, DisplayName string
+	Reason              string
+	Until               time.Time // zero until cleared
+	Count               int
+}
 
 type PRLink struct {
 	URL     string
@@ -37,27 +54,92 @@ type PRLink struct {
 }
 
 type ActiveMember struct {
-	DisplayName  string
-	Messages     []MessageLink
-	GitHubPRs    []PRLink
+	UserID      string
+	DisplayName string
+	Messages    []MessageLink
+	GitHubPRs   []PRLink
+	Count       int // distinct PRs across Slack and GitHub
+	Required    int // PRs expected in the window
+	Trend       Trend
 }
 
 type Report struct {
 	Mode, Source, Workspace string
-	From, To               time.Time
-	ByDay                  bool
-	RoyalZombies           []MemberReport
-	OtherZombies     []MemberReport
-	Active           []ActiveMember
-	TotalCount       int
-	ChannelCount     int
+	From, To                time.Time
+	ByDay                   bool
+	RoyalZombies            []MemberReport
+	OtherZombies            []MemberReport
+	BelowExpectation        []ActiveMember
+	Active                  []ActiveMember
+	OffSchedule             []MemberReport
+	Excused                 []ExcusedMember
+	TotalCount              int
+	ChannelCount            int
+	SummaryOnly             bool // render zombies and totals without member links
+	History                 bool // streaks and trends are filled in
+	ChronicAfter            int  // streak length at which a zombie is chronic
+	Interactive             bool // render excuse buttons, which need an interaction endpoint
+
+	template *template.Template // text report template; nil uses the default
+	locale   *Locale            // nil renders in English
+}
+
+func (r *Report) loc() *Locale {
+	if r.locale == nil {
+		return localeEN
+	}
+	return r.locale
 }
 
 type scanTarget struct{ id, name string }
 type member struct{ id, name string }
 
-func DetectZombies(client *SlackClient, cfg *Config, mode, source string, daysOverride int, byDay bool) (*Report, error) {
-	from, to := timeRange(mode, daysOverride)
+// DetectOptions selects what DetectZombies scans and how.
+type DetectOptions struct {
+	Mode, Source string
+	From, To     time.Time
+	ByDay        bool
+	Cache        *MessageCache      // nil fetches every message from Slack
+	Excusals     map[string]Excusal // by user ID
+	Trace        *DetectTrace       // nil traces nothing
+}
+
+// DetectTrace receives the intermediate results of DetectZombies, so that
+// explain can follow a member through the same steps as a report. Any hook
+// may be nil.
+type DetectTrace struct {
+	Member   func(userID, name string, whitelisted bool)                  // each member of the population channel
+	Channel  func(id, name string, messages []slack.Message, err error)   // each Slack channel scanned
+	GitHub   func(prs []GitHubPR, err error)                              // the org's PRs, when GitHub is scanned
+	Classify func(m ActiveMember, class Classification, excused *Excusal) // each tracked member; excused when an excusal applied
+}
+
+func (t *DetectTrace) member(userID, name string, whitelisted bool) {
+	if t != nil && t.Member != nil {
+		t.Member(userID, name, whitelisted)
+	}
+}
+
+func (t *DetectTrace) channel(id, name string, messages []slack.Message, err error) {
+	if t != nil && t.Channel != nil {
+		t.Channel(id, name, messages, err)
+	}
+}
+
+func (t *DetectTrace) github(prs []GitHubPR, err error) {
+	if t != nil && t.GitHub != nil {
+		t.GitHub(prs, err)
+	}
+}
+
+func (t *DetectTrace) classify(m ActiveMember, class Classification, excused *Excusal) {
+	if t != nil && t.Classify != nil {
+		t.Classify(m, class, excused)
+	}
+}
+
+func DetectZombies(client *SlackClient, cfg *Config, opts DetectOptions) (*Report, error) {
+	mode, source, from, to := opts.Mode, opts.Source, opts.From, opts.To
 	useSlack := source == "slack" || source == "both"
 	useGitHub := source == "github" || source == "both"
 
@@ -78,7 +160,9 @@ func DetectZombies(client *SlackClient, cfg *Config, mode, source string, daysOv
 		if name == "" {
 			name, _ = client.GetUserDisplayName(uid)
 		}
-		if cfg.IsWhitelisted(uid, name) {
+		whitelisted := cfg.IsWhitelisted(uid, name)
+		opts.Trace.member(uid, name, whitelisted)
+		if whitelisted {
 			continue
 		}
 		tracked = append(tracked, member{uid, name})
@@ -92,7 +176,7 @@ func DetectZombies(client *SlackClient, cfg *Config, mode, source string, daysOv
 		if err != nil {
 			return nil, err
 		}
-		userMessages, channelCount = scanForPRs(scanClient, targets, from, to)
+		userMessages, channelCount = scanForPRs(scanClient, opts.Cache, opts.Trace, targets, from, to)
 	}
 
 	// GitHub scan
@@ -100,7 +184,10 @@ func DetectZombies(client *SlackClient, cfg *Config, mode, source string, daysOv
 	if useGitHub && cfg.GitHubToken != "" && cfg.GitHubOrg != "" {
 		ghClient := NewGitHubClient(cfg.GitHubToken, cfg.GitHubOrg)
 		prs, err := ghClient.FetchPRs(from, to)
-		if err == nil {
+		opts.Trace.github(prs, err)
+		if err != nil {
+			slog.Warn("github: skipping PR scan", "org", cfg.GitHubOrg, "err", err)
+		} else {
 			for _, pr := range prs {
 				if displayName, ok := cfg.GitHubUsers[pr.Author]; ok {
 					ghPRsByName[displayName] = append(ghPRsByName[displayName], PRLink{
@@ -111,50 +198,60 @@ func DetectZombies(client *SlackClient, cfg *Config, mode, source string, daysOv
 		}
 	}
 
-	var royalZombies, otherZombies []MemberReport
-	var active []ActiveMember
+	var royalZombies, otherZombies, offSchedule []MemberReport
+	var active, below []ActiveMember
+	var excused []ExcusedMember
 	for _, m := range tracked {
 		msgs := userMessages[m.id]
 		ghPRs := ghPRsByName[m.name]
-		if len(msgs) > 0 || len(ghPRs) > 0 {
-			active = append(active, ActiveMember{m.name, msgs, ghPRs})
-		} else if cfg.IsRoyal(m.id, m.name) {
-			royalZombies = append(royalZombies, MemberReport{m.name})
-		} else {
-			otherZombies = append(otherZombies, MemberReport{m.name})
+		count := activityCount(msgs, ghPRs)
+		required := cfg.ExpectationFor(m.id, m.name).Required(from, to)
+		am := ActiveMember{UserID: m.id, DisplayName: m.name, Messages: msgs, GitHubPRs: ghPRs, Count: count, Required: required}
+		class := Classify(count, required)
+		slog.Debug("classify", "user", m.name, "user_id", m.id, "slack_prs", len(msgs), "github_prs", len(ghPRs),
+			"prs", count, "required", required, "class", class)
+		if e, ok := opts.Excusals[m.id]; ok && (class == ClassZombie || class == ClassBelow) && e.Covers(from, to) {
+			slog.Debug("classify: excused", "user", m.name, "user_id", m.id, "reason", e.Reason)
+			opts.Trace.classify(am, class, &e)
+			excused = append(excused, ExcusedMember{UserID: m.id, DisplayName: m.name, Reason: e.Reason, Until: e.Until, Count: count})
+			continue
+		}
+		opts.Trace.classify(am, class, nil)
+		switch class {
+		case ClassActive:
+			active = append(active, am)
+		case ClassBelow:
+			below = append(below, am)
+		case ClassOffSchedule:
+			offSchedule = append(offSchedule, MemberReport{UserID: m.id, DisplayName: m.name})
+		default:
+			if cfg.IsRoyal(m.id, m.name) {
+				royalZombies = append(royalZombies, MemberReport{UserID: m.id, DisplayName: m.name})
+			} else {
+				otherZombies = append(otherZombies, MemberReport{UserID: m.id, DisplayName: m.name})
+			}
 		}
 	}
 
 	sortByName := func(a, b string) bool { return strings.ToLower(a) < strings.ToLower(b) }
 	sort.Slice(royalZombies, func(i, j int) bool { return sortByName(royalZombies[i].DisplayName, royalZombies[j].DisplayName) })
 	sort.Slice(otherZombies, func(i, j int) bool { return sortByName(otherZombies[i].DisplayName, otherZombies[j].DisplayName) })
+	sort.Slice(offSchedule, func(i, j int) bool { return sortByName(offSchedule[i].DisplayName, offSchedule[j].DisplayName) })
+	sort.Slice(excused, func(i, j int) bool { return sortByName(excused[i].DisplayName, excused[j].DisplayName) })
+	sort.Slice(below, func(i, j int) bool { return sortByName(below[i].DisplayName, below[j].DisplayName) })
 	sort.Slice(active, func(i, j int) bool { return sortByName(active[i].DisplayName, active[j].DisplayName) })
 
 	return &Report{
 		Mode: mode, Source: source, Workspace: cfg.Workspace,
-		From: from, To: to, ByDay: byDay,
+		From: from, To: to, ByDay: opts.ByDay,
 		RoyalZombies: royalZombies, OtherZombies: otherZombies,
-		Active: active, TotalCount: len(tracked), ChannelCount: channelCount,
+		BelowExpectation: below, Active: active, OffSchedule: offSchedule, Excused: excused,
+		TotalCount: len(tracked) - len(offSchedule) - len(excused), ChannelCount: channelCount,
+		Interactive: cfg.Commands.SigningSecret != "" || cfg.Commands.AppToken != "",
+		template:    cfg.reportTemplate, locale: cfg.locale,
 	}, nil
 }
 
-func timeRange(mode string, daysOverride int) (from, to time.Time) {
-	to = time.Now()
-	days := 1
-	switch mode {
-	case "weekly":
-		days = 7
-	case "deep-scan":
-		days = 1
-	}
-	if daysOverride > 0 {
-		days = daysOverride
-	}
-	from = to.AddDate(0, 0, -days)
-	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
-	return
-}
-
 func scanTargets(client *SlackClient, cfg *Config, mode string) (*SlackClient, []scanTarget, error) {
 	if mode != "deep-scan" {
 		targets := make([]scanTarget, len(cfg.Channels))
@@ -178,74 +275,81 @@ func scanTargets(client *SlackClient, cfg *Config, mode string) (*SlackClient, [
 	return uc, targets, nil
 }
 
-func scanForPRs(client *SlackClient, targets []scanTarget, from, to time.Time) (map[string][]MessageLink, int) {
+func scanForPRs(client *SlackClient, cache *MessageCache, trace *DetectTrace, targets []scanTarget, from, to time.Time) (map[string][]MessageLink, int) {
 	userMsgs := make(map[string][]MessageLink)
 	scanned := 0
+	metrics.reset("zombie_detector_channel_messages")
 	for _, ch := range targets {
-		messages, err := client.FetchMessages(ch.id, from, to)
+		var messages []slack.Message
+		var err error
+		if cache != nil {
+			messages, err = cache.FetchMessages(client, ch.id, from, to)
+		} else {
+			messages, err = client.FetchMessages(ch.id, from, to)
+		}
+		trace.channel(ch.id, ch.name, messages, err)
 		if err != nil {
+			slog.Warn("scan: skipping channel", "channel_id", ch.id, "channel", ch.name, "err", err)
 			continue
 		}
 		scanned++
+		metrics.set("zombie_detector_channel_messages", float64(len(messages)), "channel_id", ch.id, "channel", ch.name)
+		prs := 0
 		for _, msg := range messages {
 			if pr := githubPR.FindString(msg.Text); pr != "" {
 				userMsgs[msg.User] = append(userMsgs[msg.User], MessageLink{ch.id, msg.Timestamp, pr})
+				prs++
 			}
 		}
+		slog.Debug("scan: channel", "channel_id", ch.id, "channel", ch.name, "cached", cache != nil, "messages", len(messages), "prs", prs)
 	}
 	return userMsgs, scanned
 }
 
 const slackMaxLen = 3500
 
-func FormatReport(r *Report) []string {
-	modeLabels := map[string]string{"weekly": "Weekly", "deep-scan": "Deep Scan"}
-	label := modeLabels[r.Mode]
-	if label == "" {
-		label = "Daily"
+// reportTitle returns the report heading without emoji, e.g. "Zombie Report (Daily — …)".
+func reportTitle(r *Report) string {
+	l := r.loc()
+	modeKey := "mode." + r.Mode
+	if _, ok := localeEN.Messages[modeKey]; !ok {
+		modeKey = "mode.daily"
 	}
+	return fmt.Sprintf(l.T("title"), l.T(modeKey), l.FormatTime(r.From), l.FormatTime(r.To))
+}
 
-	// Collect all blocks (each block = one logical unit that shouldn't be split)
-	var blocks []string
-
-	blocks = append(blocks, fmt.Sprintf(":zombie: Zombie Report (%s — %s to %s)\n",
-		label, r.From.Format("Mon 2006-01-02 15:04"), r.To.Format("Mon 2006-01-02 15:04")))
-
-	if len(r.RoyalZombies)+len(r.OtherZombies) == 0 {
-		blocks = append(blocks, "Everyone posted activity! No zombies detected.\n")
-	} else {
-		if s := formatGroup(":crown: *Royal Members*", r.RoyalZombies); s != "" {
-			blocks = append(blocks, s)
-		}
-		if s := formatGroup(":busts_in_silhouette: *Other Members*", r.OtherZombies); s != "" {
-			blocks = append(blocks, s)
-		}
+func reportFooter(r *Report) string {
+	l := r.loc()
+	footer := fmt.Sprintf("%s: %d/%d | %s: %s", l.T("footer.active"), len(r.Active), r.TotalCount, l.T("footer.source"), r.Source)
+	if len(r.OffSchedule) > 0 {
+		footer += fmt.Sprintf(" | %s: %d", l.T("footer.off_schedule"), len(r.OffSchedule))
 	}
-
-	if len(r.Active) > 0 {
-		blocks = append(blocks, ":white_check_mark: *Active Members*\n")
-		for _, a := range r.Active {
-			blocks = append(blocks, formatActiveMember(a, r.ByDay, r.Workspace))
-		}
+	if len(r.Excused) > 0 {
+		footer += fmt.Sprintf(" | %s: %d", l.T("footer.excused"), len(r.Excused))
 	}
-
-	footer := fmt.Sprintf("\nActive: %d/%d | Source: %s", len(r.Active), r.TotalCount, r.Source)
 	if r.ChannelCount > 0 {
-		footer += fmt.Sprintf(" | Channels: %d", r.ChannelCount)
+		footer += fmt.Sprintf(" | %s: %d", l.T("footer.channels"), r.ChannelCount)
 	}
-	blocks = append(blocks, footer+"\n")
+	return footer
+}
 
+func FormatReport(r *Report) []string {
+	return packMessages(reportUnits(r), slackMaxLen)
+}
+
+// packMessages packs units into messages of at most maxLen bytes.
+func packMessages(blocks []string, maxLen int) []string {
 	// Split oversized blocks at safe boundaries (never inside <...> hyperlinks)
 	var safeBlocks []string
 	for _, block := range blocks {
-		safeBlocks = append(safeBlocks, splitSafe(block, slackMaxLen)...)
+		safeBlocks = append(safeBlocks, splitSafe(block, maxLen)...)
 	}
 
-	// Pack blocks into messages without exceeding Slack limit
+	// Pack blocks into messages without exceeding the limit
 	var messages []string
 	var current strings.Builder
 	for _, block := range safeBlocks {
-		if current.Len()+len(block) > slackMaxLen && current.Len() > 0 {
+		if current.Len()+len(block) > maxLen && current.Len() > 0 {
 			messages = append(messages, current.String())
 			current.Reset()
 		}
@@ -290,16 +394,33 @@ func splitSafe(text string, maxLen int) []string {
 	return parts
 }
 
-func formatActiveMember(a ActiveMember, byDay bool, workspace string) string {
-	var b strings.Builder
+func formatActiveMember(a ActiveMember, r *Report) string {
+	return fmt.Sprintf("%s — %s\n", memberName(a), memberLinks(a, r))
+}
+
+// memberName returns "@name", with the PR count when below target and the trend arrow.
+func memberName(a ActiveMember) string {
+	name := "@" + a.DisplayName
+	if a.Count < a.Required {
+		name += fmt.Sprintf(" (%d/%d)", a.Count, a.Required)
+	}
+	if arrow := a.Trend.Arrow(); arrow != "" {
+		name += " " + arrow
+	}
+	return name
+}
+
+// memberLinks renders a member's Slack message links, deduplicated per PR and
+// optionally grouped by day, followed by their GitHub PRs.
+func memberLinks(a ActiveMember, r *Report) string {
 	var parts []string
 
 	// Slack activity
 	if len(a.Messages) > 0 {
-		if byDay {
-			parts = append(parts, formatDayLinks(a.Messages, workspace)...)
+		if r.ByDay {
+			parts = append(parts, formatDayLinks(a.Messages, r.Workspace, r.loc())...)
 		} else {
-			parts = append(parts, formatDeduped(a.Messages, workspace)...)
+			parts = append(parts, formatDeduped(a.Messages, r.Workspace)...)
 		}
 	}
 
@@ -314,133 +435,121 @@ func formatActiveMember(a ActiveMember, byDay bool, workspace string) string {
 		}
 		parts = append(parts, fmt.Sprintf("(%d) %s", len(a.GitHubPRs), strings.Join(ghLinks, " ")))
 	}
+	return strings.Join(parts, " ")
+}
 
-	fmt.Fprintf(&b, "@%s — %s\n", a.DisplayName, strings.Join(parts, " "))
-	return b.String()
+// PRGroup is the first message linking a PR and how many messages did.
+type PRGroup struct {
+	Link  MessageLink
+	Count int
 }
 
-func formatDeduped(msgs []MessageLink, workspace string) []string {
-	type prEntry struct {
-		link  MessageLink
-		count int
-	}
-	seen := make(map[string]*prEntry)
-	var order []string
+// dedupPRs groups messages by PR URL in order of first appearance.
+func dedupPRs(msgs []MessageLink) []PRGroup {
+	var groups []PRGroup
+	index := make(map[string]int)
 	for _, msg := range msgs {
-		if e, ok := seen[msg.PRURL]; ok {
-			e.count++
+		if i, ok := index[msg.PRURL]; ok {
+			groups[i].Count++
 		} else {
-			seen[msg.PRURL] = &prEntry{link: msg, count: 1}
-			order = append(order, msg.PRURL)
+			index[msg.PRURL] = len(groups)
+			groups = append(groups, PRGroup{Link: msg, Count: 1})
 		}
 	}
-	links := make([]string, len(order))
-	for i, pr := range order {
-		e := seen[pr]
-		if e.count > 1 {
-			links[i] = fmt.Sprintf("<%s|%d>(%d)", e.link.URL(workspace), i+1, e.count)
-		} else {
-			links[i] = fmt.Sprintf("<%s|%d>", e.link.URL(workspace), i+1)
-		}
+	return groups
+}
+
+func formatDeduped(msgs []MessageLink, workspace string) []string {
+	groups := dedupPRs(msgs)
+	links := make([]string, len(groups))
+	for i, g := range groups {
+		links[i] = formatPRGroup(g, i, workspace)
 	}
 	return links
 }
 
-func formatGroup(header string, members []MemberReport) string {
-	if len(members) == 0 {
-		return ""
+func formatPRGroup(g PRGroup, i int, workspace string) string {
+	if g.Count > 1 {
+		return fmt.Sprintf("<%s|%d>(%d)", g.Link.URL(workspace), i+1, g.Count)
 	}
-	names := make([]string, len(members))
-	for i, z := range members {
-		names[i] = "@" + z.DisplayName
+	return fmt.Sprintf("<%s|%d>", g.Link.URL(workspace), i+1)
+}
+
+// zombieLabel returns "@name", annotated with the zombie streak when known.
+func zombieLabel(z MemberReport, r *Report) string {
+	if !r.History {
+		return "@" + z.DisplayName
 	}
-	return fmt.Sprintf("%s\n%s\n\n", header, strings.Join(names, " | "))
+	return fmt.Sprintf("@%s (%s)", z.DisplayName, r.loc().Streak(r.Mode, z.Streak))
 }
 
-func formatDayLinks(msgs []MessageLink, workspace string) []string {
-	type dayGroup struct {
-		date time.Time
-		msgs []MessageLink
+// excusalLabel describes an excusal, e.g. "snoozed until Mon 2026-10-26".
+func excusalLabel(e ExcusedMember, l *Locale) string {
+	switch e.Reason {
+	case ExcuseToday:
+		return l.T("excused.today")
+	case ExcuseSnooze:
+		return fmt.Sprintf(l.T("excused.snooze"), l.FormatDate(e.Until))
 	}
-	groups := make(map[string]*dayGroup)
+	return l.T("excused.leave")
+}
+
+func (r *Report) isChronic(z MemberReport) bool {
+	return r.History && z.Streak >= r.ChronicAfter
+}
+
+// DayGroup is one day's messages, deduplicated by PR.
+type DayGroup struct {
+	Date    time.Time
+	NewWeek bool // the previous group is in an earlier week
+	PRs     []PRGroup
+}
+
+// groupByDay groups messages by local calendar day in date order.
+func groupByDay(msgs []MessageLink) []DayGroup {
+	byDate := make(map[string][]MessageLink)
+	dates := make(map[string]time.Time)
 	for _, msg := range msgs {
 		t := msg.Time()
 		key := t.Format("2006-01-02")
-		if g, ok := groups[key]; ok {
-			g.msgs = append(g.msgs, msg)
-		} else {
-			groups[key] = &dayGroup{date: t, msgs: []MessageLink{msg}}
+		if _, ok := dates[key]; !ok {
+			dates[key] = t
 		}
+		byDate[key] = append(byDate[key], msg)
 	}
-	sorted := make([]*dayGroup, 0, len(groups))
-	for _, g := range groups {
-		sorted = append(sorted, g)
+	groups := make([]DayGroup, 0, len(dates))
+	for key, date := range dates {
+		groups = append(groups, DayGroup{Date: date, PRs: dedupPRs(byDate[key])})
 	}
-	sort.Slice(sorted, func(i, j int) bool { return sorted[i].date.Before(sorted[j].date) })
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Date.Before(groups[j].Date) })
 
-	type dayEntry struct {
-		text    string
-		newWeek bool
-	}
-	var entries []dayEntry
-	for i, g := range sorted {
-		newWeek := false
-		if i > 0 {
-			prevWeekday := sorted[i-1].date.Weekday()
-			currWeekday := g.date.Weekday()
-			if prevWeekday == time.Sunday || (currWeekday != time.Sunday && currWeekday < prevWeekday) {
-				newWeek = true
-			}
-		}
-
-		// Deduplicate by PR URL, keep first message link per unique PR
-		type prEntry struct {
-			link  MessageLink
-			count int
-		}
-		seen := make(map[string]*prEntry)
-		var prOrder []string
-		for _, msg := range g.msgs {
-			if e, ok := seen[msg.PRURL]; ok {
-				e.count++
-			} else {
-				seen[msg.PRURL] = &prEntry{link: msg, count: 1}
-				prOrder = append(prOrder, msg.PRURL)
-			}
-		}
-
-		links := make([]string, len(prOrder))
-		for j, pr := range prOrder {
-			e := seen[pr]
-			if e.count > 1 {
-				links[j] = fmt.Sprintf("<%s|%d>(%d)", e.link.URL(workspace), j+1, e.count)
-			} else {
-				links[j] = fmt.Sprintf("<%s|%d>", e.link.URL(workspace), j+1)
-			}
-		}
-
-		dayLabel := g.date.Format("Mon")
-		if wd := g.date.Weekday(); wd == time.Saturday || wd == time.Sunday {
-			dayLabel = fmt.Sprintf("*%s*", dayLabel)
-		}
-
-		entries = append(entries, dayEntry{
-			text:    fmt.Sprintf("%s %d: %s", dayLabel, g.date.Day(), strings.Join(links, " ")),
-			newWeek: newWeek,
-		})
+	for i := 1; i < len(groups); i++ {
+		prevWeekday := groups[i-1].Date.Weekday()
+		currWeekday := groups[i].Date.Weekday()
+		groups[i].NewWeek = prevWeekday == time.Sunday || (currWeekday != time.Sunday && currWeekday < prevWeekday)
 	}
+	return groups
+}
 
+func formatDayLinks(msgs []MessageLink, workspace string, l *Locale) []string {
 	var parts []string
-	for i, e := range entries {
+	for i, g := range groupByDay(msgs) {
 		if i > 0 {
-			if e.newWeek {
+			if g.NewWeek {
 				parts = append(parts, "||")
 			} else {
 				parts = append(parts, "|")
 			}
 		}
-		parts = append(parts, e.text)
+		links := make([]string, len(g.PRs))
+		for j, pr := range g.PRs {
+			links[j] = formatPRGroup(pr, j, workspace)
+		}
+		weekday := l.Weekday(g.Date)
+		if wd := g.Date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			weekday = fmt.Sprintf("*%s*", weekday)
+		}
+		parts = append(parts, fmt.Sprintf(l.Day, weekday, g.Date.Day())+": "+strings.Join(links, " "))
 	}
 	return parts
 }
-
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/slack-go/slack"
+	"gopkg.in/yaml.v3"
+)
+
+// tokenFlags registers --config and --token for commands that only need a
+// Slack token, so they work before the rest of the config is filled in. The
+// returned function builds the client once the flags are parsed.
+func tokenFlags(flags *flag.FlagSet) func() *SlackClient {
+	configPath := flags.String("config", "config.yaml", "Config file to read slack_token from")
+	token := flags.String("token", "", "Slack bot token (default $SLACK_TOKEN, then slack_token from --config)")
+	return func() *SlackClient {
+		t := *token
+		if t == "" {
+			t = os.Getenv("SLACK_TOKEN")
+		}
+		if t == "" {
+			var err error
+			if t, err = readToken(*configPath); err != nil {
+				fatalf("config: %v", err)
+			}
+		}
+		if t == "" {
+			fatalf("no Slack token: pass --token, set SLACK_TOKEN, or set slack_token in %s", *configPath)
+		}
+		return NewSlackClient(t)
+	}
+}
+
+// readToken returns slack_token from a possibly incomplete config file, or ""
+// when the file does not exist.
+func readToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading config: %w", err)
+	}
+	var partial struct {
+		SlackToken string `yaml:"slack_token"`
+	}
+	if err := yaml.Unmarshal(data, &partial); err != nil {
+		return "", fmt.Errorf("parsing config: %w", err)
+	}
+	return partial.SlackToken, nil
+}
+
+// parseWithArg parses flags around one positional argument, which may come
+// before or after them, and returns it.
+func parseWithArg(flags *flag.FlagSet, args []string) string {
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		return ""
+	}
+	arg := flags.Arg(0)
+	_ = flags.Parse(flags.Args()[1:])
+	return arg
+}
+
+// runChannels implements "channels list", listing the channels the bot can
+// see with their IDs and whether it is a member.
+func runChannels(args []string) {
+	if len(args) == 0 || args[0] != "list" {
+		fatalf("usage: channels list [--member] [--token=xoxb-...] [filter]")
+	}
+	flags := flag.NewFlagSet("channels list", flag.ExitOnError)
+	client := tokenFlags(flags)
+	memberOnly := flags.Bool("member", false, "Only list channels the bot is a member of")
+	setupLog := logFlags(flags)
+	filter := strings.ToLower(parseWithArg(flags, args[1:]))
+	setupLog()
+
+	cfg, _ := LoadConfig(flags.Lookup("config").Value.String())
+	channels, err := client().FetchAllChannels()
+	if err != nil {
+		fatalf("channels: %v", err)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tID\tPRIVATE\tMEMBERS\tBOT MEMBER\tCONFIGURED")
+	for _, ch := range channels {
+		if (*memberOnly && !ch.IsMember) || !strings.Contains(strings.ToLower(ch.Name), filter) {
+			continue
+		}
+		configured := false
+		if cfg != nil {
+			for _, c := range cfg.Channels {
+				configured = configured || c.ID == ch.ID
+			}
+		}
+		_, _ = fmt.Fprintf(w, "#%s\t%s\t%s\t%d\t%s\t%s\n", ch.Name, ch.ID, yesNo(ch.IsPrivate), ch.NumMembers, yesNo(ch.IsMember), yesNo(configured))
+	}
+	_ = w.Flush()
+}
+
+// runUsers implements "users find <name>", listing users whose display name,
+// real name or username contains name.
+func runUsers(args []string) {
+	if len(args) == 0 || args[0] != "find" {
+		fatalf("usage: users find [--token=xoxb-...] <name>")
+	}
+	flags := flag.NewFlagSet("users find", flag.ExitOnError)
+	client := tokenFlags(flags)
+	setupLog := logFlags(flags)
+	query := parseWithArg(flags, args[1:])
+	setupLog()
+	if query == "" {
+		fatalf("usage: users find [--token=xoxb-...] <name>")
+	}
+
+	users, err := client().FetchUsers()
+	if err != nil {
+		fatalf("users: %v", err)
+	}
+	matches := findUsers(users, query)
+	if len(matches) == 0 {
+		fatalf("users: no user matches %q", query)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tDISPLAY NAME\tREAL NAME\tUSERNAME\tBOT")
+	for _, u := range matches {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", u.ID, u.Profile.DisplayName, u.RealName, u.Name, yesNo(u.IsBot))
+	}
+	_ = w.Flush()
+}
+
+// findUsers returns the users whose ID is query or whose display name, real
+// name or username contains it, ignoring case, sorted by display name.
+func findUsers(users []slack.User, query string) []slack.User {
+	q := strings.ToLower(query)
+	var out []slack.User
+	for _, u := range users {
+		if u.ID == query || strings.Contains(strings.ToLower(u.Profile.DisplayName), q) ||
+			strings.Contains(strings.ToLower(u.RealName), q) || strings.Contains(strings.ToLower(u.Name), q) {
+			out = append(out, u)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return strings.ToLower(displayName(&out[i])) < strings.ToLower(displayName(&out[j]))
+	})
+	return out
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
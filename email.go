@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig is the mail server used by email destinations.
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	StartTLS bool   `yaml:"starttls"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// EmailSink mails the report as a multipart text and HTML message.
+type EmailSink struct {
+	dest Destination
+	smtp SMTPConfig
+}
+
+func (s *EmailSink) Name() string {
+	return fmt.Sprintf("email %s (%s)", strings.Join(s.dest.To, ","), s.dest.Detail)
+}
+
+func (s *EmailSink) Deliver(r *Report) (int, error) {
+	msg, err := s.message(r)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.send(msg); err != nil {
+		return 0, fmt.Errorf("sending email: %w", err)
+	}
+	return 1, nil
+}
+
+func (s *EmailSink) Preview(w io.Writer, r *Report) error {
+	msg, err := s.message(r)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(msg)
+	return err
+}
+
+// send delivers msg over SMTP, upgrading with STARTTLS and authenticating
+// when configured.
+func (s *EmailSink) send(msg []byte) error {
+	addr := net.JoinHostPort(s.smtp.Host, strconv.Itoa(s.smtp.Port))
+	c, err := smtp.Dial(addr)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if s.smtp.StartTLS {
+		if err := c.StartTLS(&tls.Config{ServerName: s.smtp.Host}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if s.smtp.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.smtp.Username, s.smtp.Password, s.smtp.Host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	if err := c.Mail(s.smtp.From); err != nil {
+		return err
+	}
+	for _, rcpt := range s.dest.To {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("recipient %s: %w", rcpt, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message builds the MIME message with text and HTML alternatives.
+func (s *EmailSink) message(r *Report) ([]byte, error) {
+	r = reportView(r, s.dest.Detail)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	parts := []struct {
+		contentType string
+		write       func(io.Writer) error
+	}{
+		{"text/plain; charset=utf-8", func(w io.Writer) error {
+			_, err := io.WriteString(w, FormatPlainText(r))
+			return err
+		}},
+		{"text/html; charset=utf-8", func(w io.Writer) error {
+			return WriteReportHTML(w, r, nil)
+		}},
+	}
+	for _, p := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if err := p.write(qw); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	headers := [][2]string{
+		{"From", s.smtp.From},
+		{"To", strings.Join(s.dest.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", reportTitle(r))},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + mw.Boundary()},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h[0], h[1])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+var (
+	slackLink  = regexp.MustCompile(`<([^|>]+)\|([^>]+)>`)
+	slackEmoji = regexp.MustCompile(`:[a-z][a-z0-9_+-]*: ?`)
+)
+
+// FormatPlainText renders the text report without Slack markup, spelling out
+// link targets, for channels that cannot display mrkdwn.
+func FormatPlainText(r *Report) string {
+	text := strings.Join(FormatReport(r), "")
+	text = slackLink.ReplaceAllString(text, "$2 ($1)")
+	text = slackEmoji.ReplaceAllString(text, "")
+	return strings.ReplaceAll(text, "*", "")
+}
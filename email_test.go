@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP accepts one SMTP session on a local port and records the envelope
+// and message it receives. With rejectRcpt it refuses every recipient.
+type fakeSMTP struct {
+	ln         net.Listener
+	rejectRcpt bool
+	from       string
+	rcpt       []string
+	data       string
+	done       chan struct{}
+}
+
+func newFakeSMTP(t *testing.T, rejectRcpt bool) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeSMTP{ln: ln, rejectRcpt: rejectRcpt, done: make(chan struct{})}
+	t.Cleanup(func() { _ = ln.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeSMTP) port() int { return f.ln.Addr().(*net.TCPAddr).Port }
+
+func (f *fakeSMTP) serve() {
+	defer close(f.done)
+	conn, err := f.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	reply := func(s string) { _, _ = io.WriteString(conn, s+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			f.from = strings.TrimPrefix(line, "MAIL FROM:")
+			reply("250 OK")
+		case "RCPT":
+			if f.rejectRcpt {
+				reply("550 no such user")
+				continue
+			}
+			f.rcpt = append(f.rcpt, strings.TrimPrefix(line, "RCPT TO:"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(strings.TrimPrefix(l, "."))
+			}
+			f.data = b.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestEmailSinkDeliver(t *testing.T) {
+	srv := newFakeSMTP(t, false)
+	sink := &EmailSink{
+		dest: Destination{Type: "email", To: []string{"lead@example.com", "pm@example.com"}, Detail: "full"},
+		smtp: SMTPConfig{Host: "127.0.0.1", Port: srv.port(), From: "zombies@example.com"},
+	}
+	r := &Report{
+		Mode: "daily", Source: "slack",
+		From:         time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2024, 5, 14, 9, 0, 0, 0, time.UTC),
+		OtherZombies: []MemberReport{{UserID: "U1", DisplayName: "alice"}},
+		Active:       []ActiveMember{{UserID: "U2", DisplayName: "bob", Count: 1, Required: 1}},
+		TotalCount:   2,
+	}
+
+	sent, err := sink.Deliver(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 {
+		t.Errorf("sent = %d, want 1", sent)
+	}
+	select {
+	case <-srv.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP session did not finish")
+	}
+
+	if srv.from != "<zombies@example.com>" {
+		t.Errorf("MAIL FROM = %q", srv.from)
+	}
+	if got := strings.Join(srv.rcpt, " "); got != "<lead@example.com> <pm@example.com>" {
+		t.Errorf("RCPT TO = %q", got)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(srv.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Header.Get("To"); got != "lead@example.com, pm@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+	parts := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(p) // decodes quoted-printable
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[strings.SplitN(p.Header.Get("Content-Type"), ";", 2)[0]] = string(body)
+	}
+	if text := parts["text/plain"]; !strings.Contains(text, "@alice") || strings.Contains(text, "*") {
+		t.Errorf("text part without @alice or with mrkdwn:\n%s", text)
+	}
+	if html := parts["text/html"]; !strings.Contains(html, "@alice") || !strings.Contains(html, "<html") {
+		t.Errorf("HTML part without @alice:\n%s", html)
+	}
+}
+
+func TestEmailSinkRejectedRecipient(t *testing.T) {
+	srv := newFakeSMTP(t, true)
+	sink := &EmailSink{
+		dest: Destination{Type: "email", To: []string{"nobody@example.com"}},
+		smtp: SMTPConfig{Host: "127.0.0.1", Port: srv.port(), From: "zombies@example.com"},
+	}
+	_, err := sink.Deliver(&Report{Mode: "daily"})
+	if err == nil || !strings.Contains(err.Error(), "nobody@example.com") {
+		t.Errorf("Deliver error = %v, want the rejected recipient", err)
+	}
+}
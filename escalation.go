@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var bucketEscalations = []byte("escalations")
+
+// Escalation actions.
+const (
+	EscalateMember  = "dm_member"
+	EscalateManager = "dm_manager"
+	EscalateChannel = "channel"
+)
+
+// EscalationRule is one entry of the escalations config section. A rule
+// fires once per zombie streak, when the streak reaches After runs.
+type EscalationRule struct {
+	Name    string `yaml:"name"`    // identifies the rule in the audit trail; defaults to "<action>@<after>"
+	After   int    `yaml:"after"`   // zombie runs in a row
+	Mode    string `yaml:"mode"`    // only reports of this mode; empty matches every mode
+	Action  string `yaml:"action"`  // dm_member, dm_manager, or channel
+	Channel string `yaml:"channel"` // channel ID for the channel action
+}
+
+func (e *EscalationRule) validate() error {
+	if e.After <= 0 {
+		return fmt.Errorf("after must be at least 1")
+	}
+	if e.Mode != "" && !validModes[e.Mode] {
+		return fmt.Errorf("invalid mode %q", e.Mode)
+	}
+	switch e.Action {
+	case EscalateMember, EscalateManager:
+	case EscalateChannel:
+		if e.Channel == "" {
+			return fmt.Errorf("channel action needs a channel")
+		}
+	default:
+		return fmt.Errorf("invalid action %q: must be dm_member, dm_manager, or channel", e.Action)
+	}
+	if e.Name == "" {
+		e.Name = fmt.Sprintf("%s@%d", e.Action, e.After)
+	}
+	return nil
+}
+
+// EscalationRecord is one escalation in the audit trail.
+type EscalationRecord struct {
+	Rule        string    `json:"rule"`
+	Action      string    `json:"action"`
+	Target      string    `json:"target"` // user or channel ID the message went to
+	UserID      string    `json:"user_id"`
+	DisplayName string    `json:"display_name"`
+	Mode        string    `json:"mode"`
+	Streak      int       `json:"streak"`
+	StreakFrom  time.Time `json:"streak_from"`
+	PeriodFrom  time.Time `json:"period_from"`
+	SentAt      time.Time `json:"sent_at"`
+}
+
+func (s *Store) SaveEscalation(rec EscalationRecord) error {
+	key := rec.SentAt.UTC().Format(time.RFC3339Nano) + "|" + rec.UserID + "|" + rec.Rule
+	if err := s.put(bucketEscalations, key, rec); err != nil {
+		return fmt.Errorf("saving escalation: %w", err)
+	}
+	return nil
+}
+
+// Escalations returns the escalations sent in [from, to), oldest first.
+// Zero bounds are open-ended.
+func (s *Store) Escalations(from, to time.Time) ([]EscalationRecord, error) {
+	var start, end string
+	if !from.IsZero() {
+		start = from.UTC().Format(time.RFC3339Nano)
+	}
+	if !to.IsZero() {
+		end = to.UTC().Format(time.RFC3339Nano)
+	}
+	var out []EscalationRecord
+	err := s.scan(bucketEscalations, start, end, func(_, v []byte) error {
+		var rec EscalationRecord
+		if err := json.Unmarshal(v, &rec); err != nil {
+			return err
+		}
+		out = append(out, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading escalations: %w", err)
+	}
+	return out, nil
+}
+
+// managerOf returns a member's manager from the managers config section, or
+// else from the manager_field profile field. It is "" when neither names one.
+func (c *Config) managerOf(client *SlackClient, userID, displayName string) (string, error) {
+	for entry, manager := range c.Managers {
+		if entry == userID || strings.EqualFold(entry, displayName) {
+			return manager, nil
+		}
+	}
+	if c.ManagerField == "" {
+		return "", nil
+	}
+	value, err := client.GetProfileField(userID, c.ManagerField)
+	if err != nil {
+		return "", err
+	}
+	// user fields hold IDs, separated by commas when there are several
+	first, _, _ := strings.Cut(value, ",")
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(first), "<@"), ">"), nil
+}
+
+// Escalation is a planned escalation message. Skip says why it is not sent.
+type Escalation struct {
+	Record EscalationRecord
+	Text   string
+	Skip   string
+}
+
+// planEscalations matches the report's zombies against the escalation rules.
+// A rule already sent during a member's current streak is skipped, as is one
+// whose manager cannot be looked up.
+func planEscalations(cfg *Config, client *SlackClient, store *Store, r *Report) ([]Escalation, error) {
+	if len(cfg.Escalations) == 0 || !r.History {
+		return nil, nil
+	}
+	sent, err := store.Escalations(time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[string]EscalationRecord) // by user ID and rule
+	for _, rec := range sent {
+		last[rec.UserID+"|"+rec.Rule] = rec
+	}
+
+	l := r.loc()
+	var out []Escalation
+	for _, z := range append(append([]MemberReport(nil), r.RoyalZombies...), r.OtherZombies...) {
+		for _, rule := range cfg.Escalations {
+			if z.Streak < rule.After || (rule.Mode != "" && rule.Mode != r.Mode) {
+				continue
+			}
+			e := Escalation{Record: EscalationRecord{
+				Rule: rule.Name, Action: rule.Action, UserID: z.UserID, DisplayName: z.DisplayName,
+				Mode: r.Mode, Streak: z.Streak, StreakFrom: z.StreakFrom, PeriodFrom: r.From,
+			}}
+			if prev, ok := last[z.UserID+"|"+rule.Name]; ok && !prev.PeriodFrom.Before(z.StreakFrom) {
+				e.Skip = "sent " + prev.SentAt.Local().Format("2006-01-02 15:04")
+			}
+			since := l.FormatDate(z.StreakFrom)
+			mention := "<@" + z.UserID + ">"
+			switch rule.Action {
+			case EscalateMember:
+				e.Record.Target = z.UserID
+				e.Text = fmt.Sprintf(l.T("escalation.member"), z.DisplayName, z.Streak, since)
+			case EscalateManager:
+				manager, err := cfg.managerOf(client, z.UserID, z.DisplayName)
+				switch {
+				case e.Skip != "":
+				case err != nil:
+					slog.Warn("escalation: looking up manager", "user", z.DisplayName, "user_id", z.UserID, "err", err)
+					e.Skip = err.Error()
+				case manager == "":
+					e.Skip = "no manager known"
+				}
+				e.Record.Target = manager
+				e.Text = fmt.Sprintf(l.T("escalation.manager"), mention, z.Streak, since)
+			case EscalateChannel:
+				e.Record.Target = rule.Channel
+				e.Text = fmt.Sprintf(l.T("escalation.channel"), mention, z.Streak, since)
+			}
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// sendEscalations sends the escalations due for the report and records each
+// one in the audit trail. Failures are logged and do not stop the others.
+func sendEscalations(cfg *Config, client *SlackClient, store *Store, r *Report) {
+	escalations, err := planEscalations(cfg, client, store, r)
+	if err != nil {
+		slog.Error("escalation: planning", "err", err)
+		return
+	}
+	for _, e := range escalations {
+		if e.Skip != "" {
+			continue
+		}
+		if _, _, err := client.Post(e.Record.Target, SlackPost{Text: e.Text}, ""); err != nil {
+			slog.Error("escalation: sending", "rule", e.Record.Rule, "user", e.Record.DisplayName, "target", e.Record.Target, "err", err)
+			continue
+		}
+		slog.Info("escalated", "rule", e.Record.Rule, "user", e.Record.DisplayName, "streak", e.Record.Streak, "target", e.Record.Target)
+		e.Record.SentAt = time.Now()
+		if err := store.SaveEscalation(e.Record); err != nil {
+			slog.Error("escalation: saving audit record", "err", err)
+		}
+	}
+}
+
+// previewEscalations writes the escalations that would be sent.
+func previewEscalations(w io.Writer, cfg *Config, client *SlackClient, store *Store, r *Report) error {
+	if len(cfg.Escalations) == 0 {
+		return nil
+	}
+	escalations, err := planEscalations(cfg, client, store, r)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w, "=== escalations ===")
+	if len(escalations) == 0 {
+		_, _ = fmt.Fprintln(w, "No escalations due.")
+	}
+	for _, e := range escalations {
+		if e.Skip != "" {
+			_, _ = fmt.Fprintf(w, "%s for @%s: skipped (%s)\n", e.Record.Rule, e.Record.DisplayName, e.Skip)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s for @%s to %s:\n%s\n\n", e.Record.Rule, e.Record.DisplayName, e.Record.Target, e.Text)
+	}
+	return nil
+}
+
+// runEscalations implements the "escalations" subcommand, listing the audit
+// trail of sent escalations.
+func runEscalations(args []string) {
+	fs := flag.NewFlagSet("escalations", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "Path to config file")
+	user := fs.String("user", "", "Only show this user (ID or display name)")
+	fromFlag := fs.String("from", "", "Only escalations sent on or after this date (YYYY-MM-DD or RFC3339)")
+	toFlag := fs.String("to", "", "Only escalations sent on or before this date (YYYY-MM-DD or RFC3339)")
+	setupLog := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLog()
+
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		fatalf("config: %v", err)
+	}
+	from, err := ParseBoundary(*fromFlag, false)
+	if err != nil {
+		fatalf("escalations: %v", err)
+	}
+	to, err := ParseBoundary(*toFlag, true)
+	if err != nil {
+		fatalf("escalations: %v", err)
+	}
+
+	store, err := OpenStore(cfg.StatePath)
+	if err != nil {
+		fatalf("escalations: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	records, err := store.Escalations(from, to)
+	if err != nil {
+		fatalf("escalations: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SENT\tRULE\tUSER\tMODE\tSTREAK\tSINCE\tTO")
+	for _, rec := range records {
+		if *user != "" && rec.UserID != *user && !strings.EqualFold(rec.DisplayName, *user) {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t@%s\t%s\t%d\t%s\t%s\n", rec.SentAt.Local().Format("2006-01-02 15:04"), rec.Rule,
+			rec.DisplayName, rec.Mode, rec.Streak, rec.StreakFrom.Local().Format("2006-01-02"), rec.Target)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+var userMention = regexp.MustCompile(`<@[A-Z0-9]+>`)
+
+// handleInteraction handles button clicks on report messages.
+func (h *botHandler) handleInteraction(cb slack.InteractionCallback) {
+	if cb.Type != slack.InteractionTypeBlockActions {
+		return
+	}
+	for _, action := range cb.ActionCallback.BlockActions {
+		switch {
+		case strings.HasPrefix(action.ActionID, "open_pr_"):
+			// URL button: Slack opens the link, nothing to do
+		case action.ActionID == actionNudgeOptOut:
+			if err := responseURL(cb.ResponseURL)(h.optOut(cb.User.ID)); err != nil {
+				slog.Error("interaction: replying", "err", err)
+			}
+		case strings.HasPrefix(action.ActionID, actionExcuse):
+			reason := strings.TrimPrefix(action.ActionID, actionExcuse)
+			msg := h.excuse(cb.User.ID, cb.User.Name, reason, action.Value)
+			if err := responseURL(cb.ResponseURL)(msg); err != nil {
+				slog.Error("interaction: replying", "err", err)
+			}
+		default:
+			slog.Warn("interaction: unknown action", "user_id", cb.User.ID, "action", action.ActionID)
+		}
+	}
+}
+
+// excuse records or clears an excusal from an excuse button. value is the
+// member's user ID and the report period start as a Unix time.
+func (h *botHandler) excuse(userID, userName, reason, value string) *slack.WebhookMessage {
+	if !h.cfg.Commands.authorized(userID) {
+		return ephemeral("Sorry, you are not allowed to excuse members.")
+	}
+	member, fromText, _ := strings.Cut(value, " ")
+	from, err := strconv.ParseInt(fromText, 10, 64)
+	if member == "" || err != nil {
+		slog.Warn("interaction: invalid excuse value", "user_id", userID, "value", value)
+		return ephemeral("Sorry, that button is not valid.")
+	}
+	slog.Info("interaction: excuse", "user", userName, "user_id", userID, "member", member, "reason", reason)
+
+	l := h.cfg.locale
+	var label string
+	if reason == excuseClear {
+		err = h.store.ClearExcusal(member)
+		label = l.T("excused.cleared")
+	} else {
+		var e Excusal
+		if e, err = NewExcusal(member, reason, userID, time.Unix(from, 0), time.Now()); err == nil {
+			err = h.store.SaveExcusal(e)
+			label = excusalLabel(ExcusedMember{Reason: e.Reason, Until: e.Until}, l)
+		}
+	}
+	if err != nil {
+		slog.Error("interaction: saving excusal", "member", member, "err", err)
+		return ephemeral("Sorry, the excusal could not be saved.")
+	}
+	name, err := h.client.GetUserDisplayName(member)
+	if err != nil {
+		name = member
+	}
+	return ephemeral(fmt.Sprintf("@%s: %s", name, label))
+}
+
+// optOut stops nudges to the member who clicked the nudge's opt-out button.
+func (h *botHandler) optOut(userID string) *slack.WebhookMessage {
+	slog.Info("interaction: nudge opt-out", "user_id", userID)
+	st, err := h.store.NudgeState(userID)
+	if err == nil {
+		st.OptedOut = true
+		err = h.store.SaveNudgeState(st)
+	}
+	if err != nil {
+		slog.Error("interaction: saving nudge opt-out", "user_id", userID, "err", err)
+		return ephemeral("Sorry, your choice could not be saved.")
+	}
+	return ephemeral(h.cfg.locale.T("nudge.opted_out"))
+}
+
+// handleMention handles "@zombie-bot report weekly #channel", replying in
+// a thread under the mention.
+func (h *botHandler) handleMention(ev *slackevents.AppMentionEvent) {
+	if ev.BotID != "" {
+		return
+	}
+	text := strings.TrimSpace(userMention.ReplaceAllString(ev.Text, ""))
+	fields := strings.Fields(text)
+	if len(fields) > 0 && strings.EqualFold(fields[0], "report") {
+		text = strings.Join(fields[1:], " ")
+	}
+	slog.Info("mention", "user_id", ev.User, "channel", ev.Channel, "text", text)
+
+	thread := ev.ThreadTimeStamp
+	if thread == "" {
+		thread = ev.TimeStamp
+	}
+	// like slash command replies, ephemeral ones are shown to the requester only
+	respond := func(msg *slack.WebhookMessage) error {
+		if msg.ResponseType == slack.ResponseTypeEphemeral {
+			return h.client.PostEphemeral(ev.Channel, ev.User, msg.Text, thread)
+		}
+		_, _, err := h.client.Post(ev.Channel, SlackPost{Text: msg.Text}, thread)
+		return err
+	}
+	if err := respond(h.handleRequest(ev.User, text, respond)); err != nil {
+		slog.Error("mention: replying", "err", err)
+	}
+}
+
+// handleEvent dispatches an Events API event.
+func (h *botHandler) handleEvent(ev slackevents.EventsAPIEvent) {
+	if ev.Type != slackevents.CallbackEvent {
+		return
+	}
+	if mention, ok := ev.InnerEvent.Data.(*slackevents.AppMentionEvent); ok {
+		h.handleMention(mention)
+	}
+}
+
+// serveInteraction handles button actions posted by Slack.
+func (h *botHandler) serveInteraction(w http.ResponseWriter, r *http.Request) {
+	if err := verifySlackRequest(r, h.cfg.Commands.SigningSecret); err != nil {
+		slog.Warn("interaction: rejected request", "err", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var cb slack.InteractionCallback
+	if err := json.Unmarshal([]byte(r.FormValue("payload")), &cb); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	go h.handleInteraction(cb)
+}
+
+// serveEvents handles Events API requests, answering Slack's URL
+// verification challenge and ignoring redeliveries.
+func (h *botHandler) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if err := verifySlackRequest(r, h.cfg.Commands.SigningSecret); err != nil {
+		slog.Warn("events: rejected request", "err", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	ev, err := slackevents.ParseEvent(body, slackevents.OptionNoVerifyToken())
+	if err != nil {
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+	if ev.Type == slackevents.URLVerification {
+		var challenge slackevents.ChallengeResponse
+		if err := json.Unmarshal(body, &challenge); err != nil {
+			http.Error(w, "invalid challenge", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(challenge.Challenge))
+		return
+	}
+	if r.Header.Get("X-Slack-Retry-Num") != "" {
+		return
+	}
+	go h.handleEvent(ev)
+}
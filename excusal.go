@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+var bucketExcusals = []byte("excusals")
+
+// Excusal reasons, one per report button.
+const (
+	ExcuseToday  = "today"
+	ExcuseSnooze = "snooze"
+	ExcuseLeave  = "leave"
+)
+
+const snoozeDays = 7
+
+// Excuse buttons use the action ID actionExcuse+reason, or
+// actionExcuse+excuseClear to end an excusal.
+const (
+	actionExcuse = "excuse_"
+	excuseClear  = "clear"
+)
+
+// Excusal exempts a member from being reported as a zombie or below
+// expectation in periods within [From, Until). A zero Until lasts until the
+// excusal is cleared.
+type Excusal struct {
+	UserID    string    `json:"user_id"`
+	Reason    string    `json:"reason"`
+	From      time.Time `json:"from"`
+	Until     time.Time `json:"until,omitzero"`
+	By        string    `json:"by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewExcusal builds the excusal a button click asks for. It starts with the
+// period of the report the button was on: "today" lasts until the end of the
+// day, "snooze" for a week, and "leave" until cleared.
+func NewExcusal(userID, reason, by string, periodFrom, now time.Time) (Excusal, error) {
+	e := Excusal{UserID: userID, Reason: reason, From: periodFrom, By: by, CreatedAt: now}
+	switch reason {
+	case ExcuseToday:
+		e.Until = startOfDay(now).AddDate(0, 0, 1)
+	case ExcuseSnooze:
+		e.Until = now.AddDate(0, 0, snoozeDays)
+	case ExcuseLeave:
+	default:
+		return e, fmt.Errorf("invalid excusal reason %q", reason)
+	}
+	return e, nil
+}
+
+// Covers reports whether the excusal applies to the period [from, to), which
+// it must contain entirely: excusing a day does not excuse the week.
+func (e Excusal) Covers(from, to time.Time) bool {
+	return !e.From.After(from) && (e.Until.IsZero() || !e.Until.Before(to))
+}
+
+// SaveExcusal records e, replacing any earlier excusal of the same member.
+func (s *Store) SaveExcusal(e Excusal) error {
+	if err := s.put(bucketExcusals, e.UserID, e); err != nil {
+		return fmt.Errorf("saving excusal: %w", err)
+	}
+	return nil
+}
+
+// ClearExcusal removes a member's excusal.
+func (s *Store) ClearExcusal(userID string) error {
+	if err := s.delete(bucketExcusals, userID); err != nil {
+		return fmt.Errorf("clearing excusal: %w", err)
+	}
+	return nil
+}
+
+// Excusals returns every recorded excusal by user ID.
+func (s *Store) Excusals() (map[string]Excusal, error) {
+	out := make(map[string]Excusal)
+	err := s.scan(bucketExcusals, "", "", func(_, v []byte) error {
+		var e Excusal
+		if err := json.Unmarshal(v, &e); err != nil {
+			return err
+		}
+		out[e.UserID] = e
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading excusals: %w", err)
+	}
+	return out, nil
+}
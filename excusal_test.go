@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewExcusal(t *testing.T) {
+	periodFrom := time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 5, 15, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		reason  string
+		until   time.Time
+		wantErr bool
+	}{
+		{reason: ExcuseToday, until: time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC)},
+		{reason: ExcuseSnooze, until: now.AddDate(0, 0, 7)},
+		{reason: ExcuseLeave},
+		{reason: "holiday", wantErr: true},
+	}
+	for _, tt := range tests {
+		e, err := NewExcusal("U1", tt.reason, "U0LEAD", periodFrom, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewExcusal(%q) error = %v", tt.reason, err)
+			continue
+		}
+		if err == nil && (!e.From.Equal(periodFrom) || !e.Until.Equal(tt.until)) {
+			t.Errorf("NewExcusal(%q) = [%s, %s), want [%s, %s)", tt.reason, e.From, e.Until, periodFrom, tt.until)
+		}
+	}
+}
+
+func TestExcusalCovers(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2024, 5, d, h, 0, 0, 0, time.UTC) }
+	// "excuse today" clicked on Wednesday the 15th on the daily report of the 14th
+	today := Excusal{From: day(14, 0), Until: day(16, 0)}
+	leave := Excusal{From: day(14, 0)}
+
+	tests := []struct {
+		name     string
+		e        Excusal
+		from, to time.Time
+		want     bool
+	}{
+		{"the report it was given on", today, day(14, 0), day(15, 9), true},
+		{"a rerun of that report later today", today, day(14, 0), day(15, 17), true},
+		{"next morning's daily report", today, day(15, 0), day(16, 9), false},
+		{"the week containing the day", today, day(13, 0), day(20, 0), false},
+		{"an earlier period", today, day(7, 0), day(14, 0), false},
+		{"leave covers later periods", leave, day(20, 0), day(27, 0), true},
+		{"leave does not cover earlier ones", leave, day(7, 0), day(15, 0), false},
+	}
+	for _, tt := range tests {
+		if got := tt.e.Covers(tt.from, tt.to); got != tt.want {
+			t.Errorf("%s: Covers(%s, %s) = %v, want %v", tt.name, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Expectation describes how much activity a group of members is expected to show.
+type Expectation struct {
+	Members []string `yaml:"members"` // user IDs or display names
+	MinPRs  int      `yaml:"min_prs"`
+	Per     string   `yaml:"per"`  // day, week, or period (default week)
+	Days    []string `yaml:"days"` // working weekdays, e.g. [mon, wed, fri]; empty = every day
+
+	weekdays map[time.Weekday]bool
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func (e *Expectation) validate() error {
+	switch e.Per {
+	case "":
+		e.Per = "week"
+	case "day", "week", "period":
+	default:
+		return fmt.Errorf("invalid per %q: must be day, week, or period", e.Per)
+	}
+	if e.MinPRs < 0 {
+		return fmt.Errorf("min_prs must not be negative")
+	}
+	e.weekdays = make(map[time.Weekday]bool, len(e.Days))
+	for _, d := range e.Days {
+		wd, ok := parseWeekday(d)
+		if !ok {
+			return fmt.Errorf("invalid day %q", d)
+		}
+		e.weekdays[wd] = true
+	}
+	return nil
+}
+
+// parseWeekday accepts a weekday's three-letter abbreviation or full name in
+// any case.
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	if wd, ok := weekdayNames[s]; ok {
+		return wd, true
+	}
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if s == strings.ToLower(wd.String()) {
+			return wd, true
+		}
+	}
+	return 0, false
+}
+
+// worksOn reports whether the member is scheduled on the given weekday.
+func (e *Expectation) worksOn(wd time.Weekday) bool {
+	return e == nil || len(e.weekdays) == 0 || e.weekdays[wd]
+}
+
+// scheduledDays counts the whole calendar days in [from, to) the member is
+// scheduled on. Partial days at either end, such as the current day of a
+// rolling window, do not count.
+func (e *Expectation) scheduledDays(from, to time.Time) int {
+	d := startOfDay(from)
+	if d.Before(from) {
+		d = d.AddDate(0, 0, 1)
+	}
+	n := 0
+	for ; !d.AddDate(0, 0, 1).After(to); d = d.AddDate(0, 0, 1) {
+		if e.worksOn(d.Weekday()) {
+			n++
+		}
+	}
+	return n
+}
+
+// Required returns the number of PRs expected in [from, to), prorating weekly
+// targets over whole scheduled days and rounding up, so that a window with any
+// scheduled day needs at least one PR. Members without an expectation need one PR.
+func (e *Expectation) Required(from, to time.Time) int {
+	if e == nil {
+		return 1
+	}
+	days := e.scheduledDays(from, to)
+	switch e.Per {
+	case "day":
+		return e.MinPRs * days
+	case "period":
+		if days == 0 {
+			return 0
+		}
+		return e.MinPRs
+	}
+	perWeek := 7
+	if len(e.weekdays) > 0 {
+		perWeek = len(e.weekdays)
+	}
+	return int(math.Ceil(float64(e.MinPRs*days) / float64(perWeek)))
+}
+
+// Classification is the outcome of evaluating a member against their expectation.
+type Classification string
+
+const (
+	ClassActive      Classification = "active"
+	ClassBelow       Classification = "below"
+	ClassZombie      Classification = "zombie"
+	ClassOffSchedule Classification = "off-schedule"
+	ClassExcused     Classification = "excused"
+)
+
+// Classify compares a member's activity count against what the window requires.
+func Classify(count, required int) Classification {
+	switch {
+	case count > 0 && count >= required:
+		return ClassActive
+	case count > 0:
+		return ClassBelow
+	case required == 0:
+		return ClassOffSchedule
+	default:
+		return ClassZombie
+	}
+}
+
+// activityCount counts distinct PRs across Slack messages and GitHub results.
+func activityCount(msgs []MessageLink, prs []PRLink) int {
+	seen := make(map[string]bool)
+	for _, m := range msgs {
+		seen[normalizePRURL(m.PRURL)] = true
+	}
+	for _, pr := range prs {
+		seen[normalizePRURL(pr.URL)] = true
+	}
+	return len(seen)
+}
+
+func normalizePRURL(u string) string {
+	u = strings.TrimPrefix(u, "https://")
+	u = strings.TrimPrefix(u, "http://")
+	return strings.TrimPrefix(u, "www.")
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpectationRequired(t *testing.T) {
+	// Monday 2024-05-13 to the following Monday
+	mon := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+	weekdays := []string{"mon", "tue", "wed", "thu", "fri"}
+
+	tests := []struct {
+		name     string
+		exp      *Expectation
+		from, to time.Time
+		want     int
+	}{
+		{name: "no expectation", exp: nil, from: mon, to: mon.AddDate(0, 0, 1), want: 1},
+		{name: "weekly over a week", exp: &Expectation{MinPRs: 2, Days: weekdays}, from: mon, to: mon.AddDate(0, 0, 7), want: 2},
+		{name: "weekly over one weekday rounds up", exp: &Expectation{MinPRs: 2, Days: weekdays}, from: mon, to: mon.AddDate(0, 0, 1), want: 1},
+		{name: "weekly over three weekdays", exp: &Expectation{MinPRs: 2, Days: weekdays}, from: mon, to: mon.AddDate(0, 0, 3), want: 2},
+		{name: "weekly on a day off", exp: &Expectation{MinPRs: 2, Days: weekdays}, from: mon.AddDate(0, 0, 5), to: mon.AddDate(0, 0, 7), want: 0},
+		{name: "weekly every day over two weeks", exp: &Expectation{MinPRs: 3}, from: mon, to: mon.AddDate(0, 0, 14), want: 6},
+		{name: "zero target", exp: &Expectation{MinPRs: 0}, from: mon, to: mon.AddDate(0, 0, 7), want: 0},
+		{name: "daily", exp: &Expectation{MinPRs: 1, Per: "day", Days: []string{"mon", "wed"}}, from: mon, to: mon.AddDate(0, 0, 7), want: 2},
+		{name: "per period", exp: &Expectation{MinPRs: 4, Per: "period", Days: []string{"fri"}}, from: mon, to: mon.AddDate(0, 0, 7), want: 4},
+		{name: "per period off schedule", exp: &Expectation{MinPRs: 4, Per: "period", Days: []string{"fri"}}, from: mon, to: mon.AddDate(0, 0, 2), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.exp != nil {
+				if err := tt.exp.validate(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := tt.exp.Required(tt.from, tt.to); got != tt.want {
+				t.Errorf("Required = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectationRequiredRollingWindows(t *testing.T) {
+	// Wednesday morning: the current day is unfinished and must not count
+	now := time.Date(2024, 5, 15, 9, 0, 0, 0, time.UTC)
+	weekdays := []string{"mon", "tue", "wed", "thu", "fri"}
+
+	tests := []struct {
+		name string
+		mode string
+		exp  Expectation
+		want int
+	}{
+		{name: "weekly target in a weekly run", mode: "weekly", exp: Expectation{MinPRs: 2, Days: weekdays}, want: 2},
+		{name: "weekly target every day in a weekly run", mode: "weekly", exp: Expectation{MinPRs: 2}, want: 2},
+		{name: "weekly target in a daily run", mode: "daily", exp: Expectation{MinPRs: 2, Days: weekdays}, want: 1},
+		{name: "daily target in a daily run", mode: "daily", exp: Expectation{MinPRs: 1, Per: "day"}, want: 1},
+		{name: "daily target in a weekly run", mode: "weekly", exp: Expectation{MinPRs: 1, Per: "day", Days: weekdays}, want: 5},
+		{name: "off the previous day", mode: "daily", exp: Expectation{MinPRs: 1, Per: "day", Days: []string{"wed"}}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := ResolvePeriod(tt.mode, PeriodOptions{Now: now})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.exp.validate(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.exp.Required(from, to); got != tt.want {
+				t.Errorf("Required(%s, %s) = %d, want %d", from.Format(time.RFC3339), to.Format(time.RFC3339), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectationRequiredPartialDays(t *testing.T) {
+	start := time.Date(2024, 5, 13, 12, 0, 0, 0, time.UTC) // Monday noon
+	e := Expectation{MinPRs: 1, Per: "day"}
+	if err := e.validate(); err != nil {
+		t.Fatal(err)
+	}
+	// Monday and Thursday are partial, Tuesday and Wednesday are whole
+	if got := e.Required(start, start.AddDate(0, 0, 3)); got != 2 {
+		t.Errorf("Required = %d, want 2", got)
+	}
+}
+
+func TestExpectationValidateDays(t *testing.T) {
+	tests := []struct {
+		days    []string
+		want    []time.Weekday
+		wantErr bool
+	}{
+		{days: []string{"mon", "Wed", "FRI"}, want: []time.Weekday{time.Monday, time.Wednesday, time.Friday}},
+		{days: []string{"Monday", "sunday"}, want: []time.Weekday{time.Monday, time.Sunday}},
+		{days: []string{"mond"}, wantErr: true},
+		{days: []string{"m"}, wantErr: true},
+		{days: []string{""}, wantErr: true},
+		{days: []string{"ẞẞẞ"}, wantErr: true},
+		{days: []string{"mo\xffn"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		e := Expectation{MinPRs: 1, Days: tt.days}
+		err := e.validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validate(%q) err = %v, wantErr %v", tt.days, err, tt.wantErr)
+			continue
+		}
+		for _, wd := range tt.want {
+			if !e.worksOn(wd) {
+				t.Errorf("validate(%q): not scheduled on %s", tt.days, wd)
+			}
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		count, required int
+		want            Classification
+	}{
+		{count: 0, required: 1, want: ClassZombie},
+		{count: 0, required: 0, want: ClassOffSchedule},
+		{count: 1, required: 0, want: ClassActive},
+		{count: 1, required: 1, want: ClassActive},
+		{count: 3, required: 2, want: ClassActive},
+		{count: 1, required: 2, want: ClassBelow},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.count, tt.required); got != tt.want {
+			t.Errorf("Classify(%d, %d) = %s, want %s", tt.count, tt.required, got, tt.want)
+		}
+	}
+}
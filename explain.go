@@ -0,0 +1,265 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// runExplain implements the "explain" subcommand, which shows why one member
+// is classified the way they are.
+func runExplain(args []string) {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "Path to config file")
+	user := fs.String("user", "", "Member to explain (user ID or display name)")
+	mode := fs.String("mode", "deep-scan", "Report mode: daily, weekly, deep-scan, monthly, sprint, or previous-week")
+	source := fs.String("source", "both", "Data source: slack, github, or both")
+	days := fs.Int("days", 0, "Override time range in days (0 = use mode default)")
+	fromFlag := fs.String("from", "", "Period start as YYYY-MM-DD or RFC3339 (overrides mode)")
+	toFlag := fs.String("to", "", "Period end as YYYY-MM-DD (inclusive) or RFC3339 (overrides mode)")
+	noCache := fs.Bool("no-cache", false, "Fetch all messages from Slack instead of using the local message cache")
+	setupLog := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLog()
+
+	if *user == "" {
+		fatalf("explain: --user is required")
+	}
+	if !validModes[*mode] {
+		fatalf("explain: invalid mode %q", *mode)
+	}
+	if !validSources[*source] {
+		fatalf("explain: invalid source %q", *source)
+	}
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		fatalf("config: %v", err)
+	}
+	period := PeriodOptions{Days: *days}
+	if period.From, err = ParseBoundary(*fromFlag, false); err != nil {
+		fatalf("period: %v", err)
+	}
+	if period.To, err = ParseBoundary(*toFlag, true); err != nil {
+		fatalf("period: %v", err)
+	}
+	job := ReportJob{Mode: *mode, Source: *source, Period: period, NoCache: *noCache}
+
+	store, err := OpenStore(cfg.StatePath)
+	if err != nil {
+		slog.Warn("state unavailable, explaining without the cache and excusals", "err", err)
+		store = nil
+	} else {
+		defer func() { _ = store.Close() }()
+	}
+	if err := explainMember(os.Stdout, NewSlackClient(cfg.SlackToken), cfg, store, job, *user); err != nil {
+		fatalf("explain: %v", err)
+	}
+}
+
+// memberTrace collects what DetectZombies decided along the way.
+type memberTrace struct {
+	members     []member
+	whitelisted map[string]bool
+	channels    []channelScan
+	github      *githubScan // nil when GitHub was not scanned
+	classes     map[string]memberClass
+}
+
+type channelScan struct {
+	id, name string
+	messages []slack.Message
+	err      error
+}
+
+type githubScan struct {
+	prs []GitHubPR
+	err error
+}
+
+type memberClass struct {
+	member  ActiveMember
+	class   Classification
+	excused *Excusal
+}
+
+func (t *memberTrace) hooks() *DetectTrace {
+	t.whitelisted = make(map[string]bool)
+	t.classes = make(map[string]memberClass)
+	return &DetectTrace{
+		Member: func(userID, name string, whitelisted bool) {
+			t.members = append(t.members, member{userID, name})
+			t.whitelisted[userID] = whitelisted
+		},
+		Channel: func(id, name string, messages []slack.Message, err error) {
+			t.channels = append(t.channels, channelScan{id, name, messages, err})
+		},
+		GitHub: func(prs []GitHubPR, err error) {
+			t.github = &githubScan{prs, err}
+		},
+		Classify: func(m ActiveMember, class Classification, excused *Excusal) {
+			t.classes[m.UserID] = memberClass{m, class, excused}
+		},
+	}
+}
+
+// find looks up a member of the population by user ID or display name.
+func (t *memberTrace) find(query string) (member, bool) {
+	for _, m := range t.members {
+		if m.id == query || strings.EqualFold(m.name, query) {
+			return m, true
+		}
+	}
+	return member{}, false
+}
+
+// explainMember runs the report's detection with a trace and writes what
+// each step found for one member.
+func explainMember(w io.Writer, client *SlackClient, cfg *Config, store *Store, job ReportJob, query string) error {
+	p := func(format string, args ...any) { _, _ = fmt.Fprintf(w, format+"\n", args...) }
+
+	var t memberTrace
+	job.Trace = t.hooks()
+	report, err := runDetection(cfg, client, store, job)
+	if err != nil {
+		return err
+	}
+
+	m, inPopulation := t.find(query)
+	if !inPopulation {
+		names, err := client.FetchUserNames()
+		if err != nil {
+			return err
+		}
+		for uid, n := range names {
+			if uid == query || strings.EqualFold(n, query) {
+				m = member{uid, n}
+				break
+			}
+		}
+		if m.id == "" {
+			return fmt.Errorf("no active user with ID or display name %q", query)
+		}
+	}
+	id, name := m.id, m.name
+
+	p("Member: @%s (%s)", name, id)
+	p("Period: %s, %s – %s, source %s", report.Mode, report.From.Local().Format("2006-01-02 15:04"),
+		report.To.Local().Format("2006-01-02 15:04"), report.Source)
+
+	p("\nPopulation")
+	population := cfg.Channels[0]
+	if inPopulation {
+		p("  included: member of #%s (%s), whose members are tracked", population.Name, population.ID)
+	} else {
+		p("  not included: not a member of #%s (%s), whose members are tracked", population.Name, population.ID)
+	}
+	if t.whitelisted[id] {
+		entry, _ := matchEntry(cfg.Whitelist, id, name)
+		p("  whitelist: listed as %q, so left out of reports", entry)
+	} else {
+		p("  whitelist: not listed")
+	}
+	if entry, ok := matchEntry(cfg.RoyalMembers, id, name); ok {
+		p("  royal_members: listed as %q", entry)
+	} else {
+		p("  royal_members: not listed")
+	}
+	c, classified := t.classes[id]
+	e := cfg.ExpectationFor(id, name)
+	required := e.Required(report.From, report.To)
+	if classified {
+		required = c.member.Required
+	}
+	if e == nil {
+		p("  expectation: none, so at least 1 PR in the period")
+	} else {
+		entry, _ := matchEntry(e.Members, id, name)
+		days := "every day"
+		if len(e.Days) > 0 {
+			days = strings.Join(e.Days, ", ")
+		}
+		p("  expectation: %d PRs per %s on %s (listed as %q), %d required in this period", e.MinPRs, e.Per, days, entry, required)
+	}
+
+	if job.Source == "github" {
+		p("\nSlack: not scanned with source %s", job.Source)
+	} else {
+		p("\nSlack: %d channels", len(t.channels))
+	}
+	for _, ch := range t.channels {
+		if ch.err != nil {
+			p("  #%s (%s): not scanned: %v", ch.name, ch.id, ch.err)
+			continue
+		}
+		var own []slack.Message
+		for _, msg := range ch.messages {
+			if msg.User == id {
+				own = append(own, msg)
+			}
+		}
+		p("  #%s (%s): %d messages, %d by @%s", ch.name, ch.id, len(ch.messages), len(own), name)
+		for _, msg := range own {
+			when := MessageLink{Timestamp: msg.Timestamp}.Time().Local().Format("2006-01-02 15:04")
+			if pr := githubPR.FindString(msg.Text); pr != "" {
+				p("    %s  PR link   %s", when, pr)
+			} else {
+				p("    %s  no match  %q", when, truncate(strings.Join(strings.Fields(msg.Text), " "), 60))
+			}
+		}
+	}
+
+	switch {
+	case t.github == nil && job.Source == "slack":
+		p("\nGitHub: not scanned with source %s", job.Source)
+	case t.github == nil:
+		p("\nGitHub: not scanned, github_token and github_org are not set")
+	default:
+		p("\nGitHub: org %s", cfg.GitHubOrg)
+		var logins []string
+		for login := range cfg.GitHubUsers {
+			logins = append(logins, login)
+		}
+		sort.Strings(logins)
+		mapped := false
+		for _, login := range logins {
+			switch to := cfg.GitHubUsers[login]; {
+			case to == name:
+				mapped = true
+				p("  github_users: %s → %q", login, to)
+			case strings.EqualFold(to, name):
+				p("  github_users: %s → %q, which differs in case from %q and does not match", login, to, name)
+			}
+		}
+		if !mapped {
+			p("  github_users: no login maps to %q, so GitHub PRs are not counted", name)
+		}
+		if t.github.err != nil {
+			p("  not scanned: %v", t.github.err)
+			break
+		}
+		p("  %d PRs in the org, %d by @%s", len(t.github.prs), len(c.member.GitHubPRs), name)
+		for _, pr := range c.member.GitHubPRs {
+			p("    %s  %s  %s", pr.Created.Local().Format("2006-01-02 15:04"), pr.URL, pr.Title)
+		}
+	}
+
+	p("\nResult")
+	if !classified {
+		p("  not in reports")
+		return nil
+	}
+	p("  distinct PRs: %d of %d required (Slack links: %d, GitHub PRs: %d)",
+		c.member.Count, c.member.Required, len(c.member.Messages), len(c.member.GitHubPRs))
+	if ex := c.excused; ex != nil {
+		p("  %s, but excused: %s (by %s)", c.class, excusalLabel(ExcusedMember{Reason: ex.Reason, Until: ex.Until}, localeEN), ex.By)
+	} else {
+		p("  %s", c.class)
+	}
+	return nil
+}
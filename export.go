@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReportSchemaVersion is bumped whenever a field of the JSON report changes
+// meaning or is removed. Adding fields does not bump it.
+const ReportSchemaVersion = 1
+
+type ReportJSON struct {
+	SchemaVersion    int            `json:"schema_version"`
+	Mode             string         `json:"mode"`
+	Source           string         `json:"source"`
+	Period           PeriodJSON     `json:"period"`
+	Population       PopulationJSON `json:"population"`
+	Zombies          []ZombieJSON   `json:"zombies"`
+	BelowExpectation []ActiveJSON   `json:"below_expectation"`
+	Active           []ActiveJSON   `json:"active"`
+	OffSchedule      []MemberJSON   `json:"off_schedule"`
+	Excused          []ExcusedJSON  `json:"excused"`
+}
+
+type PeriodJSON struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type PopulationJSON struct {
+	Tracked          int `json:"tracked"`
+	Active           int `json:"active"`
+	BelowExpectation int `json:"below_expectation"`
+	Zombies          int `json:"zombies"`
+	OffSchedule      int `json:"off_schedule"`
+	Excused          int `json:"excused"`
+	Channels         int `json:"channels_scanned"`
+}
+
+type MemberJSON struct {
+	UserID      string `json:"user_id"`
+	DisplayName string `json:"display_name"`
+}
+
+type ZombieJSON struct {
+	MemberJSON
+	Royal   bool `json:"royal"`
+	Streak  int  `json:"streak,omitempty"`
+	Chronic bool `json:"chronic"`
+}
+
+type ExcusedJSON struct {
+	MemberJSON
+	Reason string    `json:"reason"`
+	Until  time.Time `json:"until,omitzero"`
+	PRs    int       `json:"prs"`
+}
+
+type ActiveJSON struct {
+	MemberJSON
+	PRs       int           `json:"prs"`
+	Required  int           `json:"required"`
+	Trend     Trend         `json:"trend,omitempty"`
+	Messages  []MessageJSON `json:"messages"`
+	GitHubPRs []GitHubJSON  `json:"github_prs"`
+}
+
+type MessageJSON struct {
+	ChannelID string    `json:"channel_id"`
+	Timestamp string    `json:"ts"`
+	Time      time.Time `json:"time"`
+	URL       string    `json:"url"`
+	PRURL     string    `json:"pr_url"`
+}
+
+type GitHubJSON struct {
+	URL     string    `json:"url"`
+	Title   string    `json:"title"`
+	Created time.Time `json:"created"`
+}
+
+// NewReportJSON converts a report into its stable JSON representation.
+func NewReportJSON(r *Report) ReportJSON {
+	out := ReportJSON{
+		SchemaVersion: ReportSchemaVersion,
+		Mode:          r.Mode,
+		Source:        r.Source,
+		Period:        PeriodJSON{From: r.From, To: r.To},
+		Population: PopulationJSON{
+			Tracked:          r.TotalCount,
+			Active:           len(r.Active),
+			BelowExpectation: len(r.BelowExpectation),
+			Zombies:          len(r.RoyalZombies) + len(r.OtherZombies),
+			OffSchedule:      len(r.OffSchedule),
+			Excused:          len(r.Excused),
+			Channels:         r.ChannelCount,
+		},
+		Zombies:          []ZombieJSON{},
+		BelowExpectation: []ActiveJSON{},
+		Active:           []ActiveJSON{},
+		OffSchedule:      []MemberJSON{},
+		Excused:          []ExcusedJSON{},
+	}
+	for _, group := range []struct {
+		members []MemberReport
+		royal   bool
+	}{{r.RoyalZombies, true}, {r.OtherZombies, false}} {
+		for _, z := range group.members {
+			out.Zombies = append(out.Zombies, ZombieJSON{
+				MemberJSON: MemberJSON{z.UserID, z.DisplayName},
+				Royal:      group.royal, Streak: z.Streak, Chronic: r.isChronic(z),
+			})
+		}
+	}
+	if !r.SummaryOnly {
+		for _, a := range r.BelowExpectation {
+			out.BelowExpectation = append(out.BelowExpectation, newActiveJSON(a, r.Workspace))
+		}
+		for _, a := range r.Active {
+			out.Active = append(out.Active, newActiveJSON(a, r.Workspace))
+		}
+	}
+	for _, m := range r.OffSchedule {
+		out.OffSchedule = append(out.OffSchedule, MemberJSON{m.UserID, m.DisplayName})
+	}
+	for _, e := range r.Excused {
+		out.Excused = append(out.Excused, ExcusedJSON{
+			MemberJSON: MemberJSON{e.UserID, e.DisplayName}, Reason: e.Reason, Until: e.Until, PRs: e.Count,
+		})
+	}
+	return out
+}
+
+func newActiveJSON(a ActiveMember, workspace string) ActiveJSON {
+	out := ActiveJSON{
+		MemberJSON: MemberJSON{a.UserID, a.DisplayName},
+		PRs:        a.Count, Required: a.Required, Trend: a.Trend,
+		Messages: []MessageJSON{}, GitHubPRs: []GitHubJSON{},
+	}
+	for _, m := range a.Messages {
+		out.Messages = append(out.Messages, MessageJSON{
+			ChannelID: m.ChannelID, Timestamp: m.Timestamp, Time: m.Time(),
+			URL: m.URL(workspace), PRURL: "https://" + normalizePRURL(m.PRURL),
+		})
+	}
+	for _, pr := range a.GitHubPRs {
+		out.GitHubPRs = append(out.GitHubPRs, GitHubJSON{URL: pr.URL, Title: pr.Title, Created: pr.Created})
+	}
+	return out
+}
+
+func WriteReportJSON(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewReportJSON(r))
+}
+
+var csvHeader = []string{
+	"period_from", "period_to", "mode", "user_id", "display_name", "class",
+	"royal", "streak", "prs", "required", "trend", "links",
+}
+
+// WriteReportCSV writes one row per tracked member.
+func WriteReportCSV(w io.Writer, r *Report) error {
+	rj := NewReportJSON(r)
+	from, to := rj.Period.From.Format(time.RFC3339), rj.Period.To.Format(time.RFC3339)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	// royal and streak are only known for zombies and left empty otherwise
+	row := func(m MemberJSON, class Classification, royal, streak string, prs, required int, trend Trend, links []string) error {
+		return cw.Write([]string{
+			from, to, rj.Mode, m.UserID, m.DisplayName, string(class),
+			royal, streak, strconv.Itoa(prs), strconv.Itoa(required),
+			string(trend), strings.Join(links, " "),
+		})
+	}
+	for _, z := range rj.Zombies {
+		var streak string
+		if z.Streak > 0 {
+			streak = strconv.Itoa(z.Streak)
+		}
+		if err := row(z.MemberJSON, ClassZombie, strconv.FormatBool(z.Royal), streak, 0, 0, "", nil); err != nil {
+			return err
+		}
+	}
+	for _, group := range []struct {
+		members []ActiveJSON
+		class   Classification
+	}{{rj.BelowExpectation, ClassBelow}, {rj.Active, ClassActive}} {
+		for _, a := range group.members {
+			var links []string
+			for _, m := range a.Messages {
+				links = append(links, m.PRURL)
+			}
+			for _, pr := range a.GitHubPRs {
+				links = append(links, pr.URL)
+			}
+			if err := row(a.MemberJSON, group.class, "", "", a.PRs, a.Required, a.Trend, links); err != nil {
+				return err
+			}
+		}
+	}
+	for _, m := range rj.OffSchedule {
+		if err := row(m, ClassOffSchedule, "", "", 0, 0, "", nil); err != nil {
+			return err
+		}
+	}
+	for _, e := range rj.Excused {
+		if err := row(e.MemberJSON, ClassExcused, "", "", e.PRs, 0, "", nil); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"time"
@@ -11,10 +12,11 @@ import (
 type GitHubClient struct {
 	token string
 	org   string
+	http  *http.Client
 }
 
 func NewGitHubClient(token, org string) *GitHubClient {
-	return &GitHubClient{token: token, org: org}
+	return &GitHubClient{token: token, org: org, http: apiHTTPClient("github")}
 }
 
 type GitHubPR struct {
@@ -30,7 +32,7 @@ func (gc *GitHubClient) FetchPRs(from, to time.Time) ([]GitHubPR, error) {
 
 	for {
 		query := fmt.Sprintf("org:%s is:pr created:%s..%s",
-			gc.org, from.Format("2006-01-02"), to.Format("2006-01-02"))
+			gc.org, from.Format(time.RFC3339), to.Format(time.RFC3339))
 
 		u := fmt.Sprintf("https://api.github.com/search/issues?q=%s&per_page=100&page=%d",
 			url.QueryEscape(query), page)
@@ -42,7 +44,7 @@ func (gc *GitHubClient) FetchPRs(from, to time.Time) ([]GitHubPR, error) {
 		req.Header.Set("Authorization", "Bearer "+gc.token)
 		req.Header.Set("Accept", "application/vnd.github+json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := gc.http.Do(req)
 		if err != nil {
 			return nil, fmt.Errorf("github api: %w", err)
 		}
@@ -75,6 +77,7 @@ func (gc *GitHubClient) FetchPRs(from, to time.Time) ([]GitHubPR, error) {
 			})
 		}
 
+		slog.Debug("github: fetched PR page", "org", gc.org, "page", page, "items", len(result.Items), "total", result.TotalCount)
 		if len(all) >= result.TotalCount || len(result.Items) == 0 {
 			break
 		}
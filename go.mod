@@ -4,7 +4,11 @@ go 1.25.0
 
 require (
 	github.com/slack-go/slack v0.17.3
+	go.etcd.io/bbolt v1.4.3
 	gopkg.in/yaml.v3 v3.0.1
 )
 
-require github.com/gorilla/websocket v1.5.3 // indirect
+require (
+	github.com/gorilla/websocket v1.5.3 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+)
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// runHistory implements the "history" subcommand, listing recorded runs.
+func runHistory(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "Path to config file")
+	user := fs.String("user", "", "Only show this user (ID or display name)")
+	fromFlag := fs.String("from", "", "Only runs starting on or after this date (YYYY-MM-DD or RFC3339)")
+	toFlag := fs.String("to", "", "Only runs starting on or before this date (YYYY-MM-DD or RFC3339)")
+	setupLog := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLog()
+
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		fatalf("config: %v", err)
+	}
+	from, err := ParseBoundary(*fromFlag, false)
+	if err != nil {
+		fatalf("history: %v", err)
+	}
+	to, err := ParseBoundary(*toFlag, true)
+	if err != nil {
+		fatalf("history: %v", err)
+	}
+
+	store, err := OpenStore(cfg.StatePath)
+	if err != nil {
+		fatalf("history: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	runs, err := store.Runs(from, to)
+	if err != nil {
+		fatalf("history: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PERIOD\tMODE\tUSER\tCLASS\tPRS\tREQUIRED")
+	for _, run := range runs {
+		period := run.From.Local().Format("2006-01-02 15:04") + " – " + run.To.Local().Format("2006-01-02 15:04")
+		for _, m := range run.Members {
+			if *user != "" && m.UserID != *user && !strings.EqualFold(m.DisplayName, *user) {
+				continue
+			}
+			class := string(m.Class)
+			if m.Royal {
+				class += " (royal)"
+			}
+			required := "-"
+			if m.Class == ClassActive || m.Class == ClassBelow {
+				required = fmt.Sprint(m.Required)
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t@%s\t%s\t%d\t%s\n", period, run.Mode, m.DisplayName, class, m.PRs, required)
+		}
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed templates/report.html templates/report.css
+var htmlAssets embed.FS
+
+var htmlTemplate = template.Must(template.New("report.html").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).ParseFS(htmlAssets, "templates/report.html"))
+
+const heatmapDays = 84
+
+// htmlData is what the HTML template executes against; the embedded locale
+// provides .T, .Streak and .FormatDate.
+type htmlData struct {
+	*Locale
+	Title     string
+	CSS       template.CSS
+	Report    ReportJSON
+	Heatmap   *heatmap
+	Footer    string
+	Generated string
+}
+
+type heatmap struct {
+	From, To string
+	Rows     []heatmapRow
+}
+
+type heatmapRow struct {
+	Name  string
+	Cells []heatmapCell
+}
+
+type heatmapCell struct {
+	Class string // none, zombie, or l0–l4 by PR count
+	Title string
+}
+
+// WriteReportHTML renders the report as a self-contained HTML page. With
+// history, it adds a per-member calendar heatmap of the last twelve weeks.
+func WriteReportHTML(w io.Writer, r *Report, runs []RunRecord) error {
+	css, err := htmlAssets.ReadFile("templates/report.css")
+	if err != nil {
+		return err
+	}
+	l := r.loc()
+	data := htmlData{
+		Locale:    l,
+		Title:     reportTitle(r),
+		CSS:       template.CSS(css),
+		Report:    NewReportJSON(r),
+		Footer:    reportFooter(r),
+		Generated: l.FormatTime(time.Now()),
+	}
+	if len(runs) > 0 {
+		data.Heatmap = buildHeatmap(r, runs)
+	}
+	return htmlTemplate.Execute(w, data)
+}
+
+// htmlTable is what the members table template executes against.
+type htmlTable struct {
+	*Locale
+	Members []ActiveJSON
+}
+
+// Table pairs members with the locale for the members table template.
+func (d htmlData) Table(members []ActiveJSON) htmlTable {
+	return htmlTable{d.Locale, members}
+}
+
+// Excusal describes why and until when a member is excused.
+func (d htmlData) Excusal(e ExcusedJSON) string {
+	return excusalLabel(ExcusedMember{Reason: e.Reason, Until: e.Until}, d.Locale)
+}
+
+// heatmapStart returns the first day the report's heatmap shows.
+func heatmapStart(r *Report) time.Time {
+	return startOfDay(r.To).AddDate(0, 0, -heatmapDays+1)
+}
+
+// buildHeatmap lays out one cell per member per day from runs covering at
+// most two days, each attributed to the day its period starts.
+func buildHeatmap(r *Report, runs []RunRecord) *heatmap {
+	end := startOfDay(r.To)
+	start := heatmapStart(r)
+
+	type dayKey struct {
+		user string
+		day  string
+	}
+	cells := make(map[dayKey]MemberRecord)
+	names := make(map[string]string)
+	all := append(append([]RunRecord(nil), runs...), NewRunRecord(r))
+	for _, run := range all {
+		if run.To.Sub(run.From) > 48*time.Hour || run.From.Before(start) {
+			continue
+		}
+		day := run.From.Local().Format(dateLayout)
+		for _, m := range run.Members {
+			cells[dayKey{m.UserID, day}] = m
+			names[m.UserID] = m.DisplayName
+		}
+	}
+	if len(cells) == 0 {
+		return nil
+	}
+
+	users := make([]string, 0, len(names))
+	for id := range names {
+		users = append(users, id)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return strings.ToLower(names[users[i]]) < strings.ToLower(names[users[j]])
+	})
+
+	l := r.loc()
+	hm := &heatmap{From: start.Format(l.Date), To: end.Format(l.Date)}
+	for _, id := range users {
+		row := heatmapRow{Name: names[id]}
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			m, ok := cells[dayKey{id, d.Format(dateLayout)}]
+			day := l.FormatDate(d) + ": "
+			cell := heatmapCell{Class: "none", Title: day + l.T("heatmap.none")}
+			switch {
+			case !ok:
+			case m.Class == ClassZombie:
+				cell = heatmapCell{Class: "zombie", Title: day + l.T("heatmap.zombie")}
+			case m.Class == ClassOffSchedule:
+				cell = heatmapCell{Class: "l0", Title: day + l.T("heatmap.off_schedule")}
+			case m.Class == ClassExcused:
+				cell = heatmapCell{Class: "l0", Title: day + l.T("heatmap.excused")}
+			default:
+				cell = heatmapCell{Class: fmt.Sprintf("l%d", min(m.PRs, 4)), Title: day + fmt.Sprintf(l.T("heatmap.prs"), m.PRs)}
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		hm.Rows = append(hm.Rows, row)
+	}
+	return hm
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteReportHTMLLocale(t *testing.T) {
+	from := time.Date(2024, 5, 13, 0, 0, 0, 0, time.Local)
+	r := &Report{
+		Mode: "daily", Source: "slack", From: from, To: from.AddDate(0, 0, 1),
+		OtherZombies:     []MemberReport{{UserID: "U1", DisplayName: "bob", Streak: 2}},
+		BelowExpectation: []ActiveMember{{UserID: "U2", DisplayName: "carol", Count: 1, Required: 2}},
+		Excused:          []ExcusedMember{{UserID: "U3", DisplayName: "erin", Reason: ExcuseSnooze, Until: from.AddDate(0, 0, 7)}},
+		History:          true,
+		TotalCount:       2,
+		locale:           localeDE,
+	}
+	runs := []RunRecord{{Mode: "daily", From: from.AddDate(0, 0, -1), To: from, Members: []MemberRecord{{UserID: "U1", DisplayName: "bob", Class: ClassZombie}}}}
+
+	var b strings.Builder
+	if err := WriteReportHTML(&b, r, runs); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`<html lang="de">`, "Zombie-Bericht", "Unter Erwartung", "@bob (2 T)", "pausiert bis Mo 20.05.2024",
+		"<th>Mitglied</th>", "Aktivität, ", "So 12.05.2024: Zombie", "erstellt ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML lacks %q", want)
+		}
+	}
+	for _, english := range []string{"Below Expectation", "no data", "generated", "until", "Member"} {
+		if strings.Contains(out, english) {
+			t.Errorf("HTML contains English %q", english)
+		}
+	}
+}
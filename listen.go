@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// runListen implements the "listen" subcommand: it serves the slash command,
+// button actions and @mentions over Socket Mode, which needs no public
+// endpoint.
+func runListen(args []string) {
+	fs := flag.NewFlagSet("listen", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "Path to config file")
+	metricsAddr := fs.String("metrics-addr", "", "Serve Prometheus metrics on this address, e.g. :9090")
+	setupLog := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLog()
+
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		fatalf("config: %v", err)
+	}
+	if cfg.Commands.AppToken == "" {
+		fatalf("config: listen needs commands.app_token")
+	}
+
+	store, err := OpenStore(cfg.StatePath)
+	if err != nil {
+		fatalf("state: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if *metricsAddr != "" {
+		serveMetrics(*metricsAddr)
+	}
+
+	bot := &botHandler{&reportRunner{cfg: cfg, client: NewSlackClient(cfg.SlackToken), store: store}}
+	sm := socketmode.New(slack.New(cfg.SlackToken, slack.OptionAppLevelToken(cfg.Commands.AppToken), slack.OptionHTTPClient(apiHTTPClient("slack"))))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		for evt := range sm.Events {
+			bot.handleSocketEvent(sm, evt)
+		}
+	}()
+	if err := sm.RunContext(ctx); err != nil && ctx.Err() == nil {
+		fatalf("listen: %v", err)
+	}
+}
+
+// handleSocketEvent acknowledges a Socket Mode event and passes it to the
+// handler shared with HTTP mode.
+func (h *botHandler) handleSocketEvent(sm *socketmode.Client, evt socketmode.Event) {
+	switch evt.Type {
+	case socketmode.EventTypeConnected:
+		slog.Info("listen: connected to Slack in Socket Mode")
+	case socketmode.EventTypeConnectionError, socketmode.EventTypeInvalidAuth:
+		slog.Warn("listen: connection problem", "event", evt.Type, "data", fmt.Sprint(evt.Data))
+	case socketmode.EventTypeSlashCommand:
+		cmd, ok := evt.Data.(slack.SlashCommand)
+		if !ok {
+			return
+		}
+		sm.Ack(*evt.Request, h.handleCommand(cmd, responseURL(cmd.ResponseURL)))
+	case socketmode.EventTypeInteractive:
+		cb, ok := evt.Data.(slack.InteractionCallback)
+		if !ok {
+			return
+		}
+		sm.Ack(*evt.Request)
+		go h.handleInteraction(cb)
+	case socketmode.EventTypeEventsAPI:
+		ev, ok := evt.Data.(slackevents.EventsAPIEvent)
+		if !ok {
+			return
+		}
+		sm.Ack(*evt.Request)
+		go h.handleEvent(ev)
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Locale is a message catalog plus date conventions for rendering reports.
+type Locale struct {
+	Name     string
+	Messages map[string]string
+	Weekdays [7]string // abbreviated, Sunday first
+	DateTime string    // time layout following the weekday in titles
+	Date     string    // date layout following the weekday
+	Day      string    // format of the weekday and day of month in --by-day links
+}
+
+// T returns the message for key, falling back to English.
+func (l *Locale) T(key string) string {
+	if s, ok := l.Messages[key]; ok {
+		return s
+	}
+	if s, ok := localeEN.Messages[key]; ok {
+		return s
+	}
+	return key
+}
+
+// Weekday returns the abbreviated weekday of t.
+func (l *Locale) Weekday(t time.Time) string {
+	return l.Weekdays[t.Weekday()]
+}
+
+// FormatTime formats t as the abbreviated weekday followed by date and time.
+func (l *Locale) FormatTime(t time.Time) string {
+	return l.Weekday(t) + " " + t.Format(l.DateTime)
+}
+
+// FormatDate formats t as the abbreviated weekday followed by the date.
+func (l *Locale) FormatDate(t time.Time) string {
+	return l.Weekday(t) + " " + t.Format(l.Date)
+}
+
+// Streak formats a streak in the unit of the report mode, e.g. "3d".
+func (l *Locale) Streak(mode string, streak int) string {
+	switch mode {
+	case "daily", "deep-scan":
+		return fmt.Sprintf(l.T("streak.days"), streak)
+	case "weekly", "previous-week":
+		return fmt.Sprintf(l.T("streak.weeks"), streak)
+	case "monthly":
+		return fmt.Sprintf(l.T("streak.months"), streak)
+	}
+	return fmt.Sprintf(l.T("streak.runs"), streak)
+}
+
+var localeEN = &Locale{
+	Name: "en",
+	Messages: map[string]string{
+		"title":                "Zombie Report (%s — %s to %s)",
+		"mode.daily":           "Daily",
+		"mode.weekly":          "Weekly",
+		"mode.deep-scan":       "Deep Scan",
+		"mode.monthly":         "Monthly",
+		"mode.sprint":          "Sprint",
+		"mode.previous-week":   "Previous Week",
+		"royal_members":        "Royal Members",
+		"other_members":        "Other Members",
+		"below_expectation":    "Below Expectation",
+		"active_members":       "Active Members",
+		"no_zombies":           "Everyone posted activity! No zombies detected.",
+		"newly_zombie":         "Newly zombie",
+		"chronic_zombie":       "Chronic zombie",
+		"footer.active":        "Active",
+		"footer.source":        "Source",
+		"footer.off_schedule":  "Off schedule",
+		"footer.channels":      "Channels",
+		"footer.excused":       "Excused",
+		"excused":              "Excused",
+		"excused.today":        "excused today",
+		"excused.snooze":       "snoozed until %s",
+		"excused.leave":        "on leave",
+		"button.today":         "Excuse today",
+		"button.snooze":        "Snooze 1 week",
+		"button.leave":         "Mark on leave",
+		"button.clear":         "End excusal",
+		"excused.cleared":      "excusal ended",
+		"nudge.greeting":       "Hi %s! :wave:",
+		"nudge.last_activity":  "The last PR we saw from you in the review channels was on %s.",
+		"nudge.no_activity":    "We haven't seen a PR from you in the review channels lately.",
+		"nudge.review":         "Got something ready for review? Share it in %s.",
+		"nudge.opt_out":        "Stop these reminders",
+		"nudge.opted_out":      "You won't get these reminders anymore.",
+		"escalation.member":    "Hi %s, you have been listed as a zombie in %d reports in a row, since %s. Is anything blocking you?",
+		"escalation.manager":   "Heads-up: %s has been listed as a zombie in %d reports in a row, since %s.",
+		"escalation.channel":   ":rotating_light: %s has been listed as a zombie in %d reports in a row, since %s.",
+		"open_pr":              "Open PR",
+		"part":                 "part %d",
+		"streak.days":          "%dd",
+		"streak.weeks":         "%dw",
+		"streak.months":        "%dmo",
+		"streak.runs":          "×%d",
+		"zombies":              "Zombies",
+		"html.member":          "Member",
+		"html.prs":             "PRs",
+		"html.links":           "Links",
+		"html.activity":        "Activity, %s – %s",
+		"html.generated":       "generated %s",
+		"heatmap.none":         "no data",
+		"heatmap.zombie":       "zombie",
+		"heatmap.off_schedule": "off schedule",
+		"heatmap.excused":      "excused",
+		"heatmap.prs":          "%d PRs",
+		"thread.parent":        ":zombie: Zombie reports for %s",
+	},
+	Weekdays: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	DateTime: "2006-01-02 15:04",
+	Date:     "2006-01-02",
+	Day:      "%s %d",
+}
+
+var localeUK = &Locale{
+	Name: "uk",
+	Messages: map[string]string{
+		"title":                "Звіт про зомбі (%s — з %s по %s)",
+		"mode.daily":           "Щоденний",
+		"mode.weekly":          "Щотижневий",
+		"mode.deep-scan":       "Глибоке сканування",
+		"mode.monthly":         "Щомісячний",
+		"mode.sprint":          "Спринт",
+		"mode.previous-week":   "Минулий тиждень",
+		"royal_members":        "Королівські учасники",
+		"other_members":        "Інші учасники",
+		"below_expectation":    "Нижче очікувань",
+		"active_members":       "Активні учасники",
+		"no_zombies":           "Усі проявили активність! Зомбі не виявлено.",
+		"newly_zombie":         "Нові зомбі",
+		"chronic_zombie":       "Хронічні зомбі",
+		"footer.active":        "Активні",
+		"footer.source":        "Джерело",
+		"footer.off_schedule":  "Поза графіком",
+		"footer.channels":      "Канали",
+		"footer.excused":       "Звільнені",
+		"excused":              "Звільнені",
+		"excused.today":        "звільнено на сьогодні",
+		"excused.snooze":       "відкладено до %s",
+		"excused.leave":        "у відпустці",
+		"button.today":         "Звільнити на сьогодні",
+		"button.snooze":        "Відкласти на тиждень",
+		"button.leave":         "Позначити відпустку",
+		"button.clear":         "Скасувати звільнення",
+		"excused.cleared":      "звільнення скасовано",
+		"nudge.greeting":       "Привіт, %s! :wave:",
+		"nudge.last_activity":  "Твій останній PR у каналах рев’ю ми бачили %s.",
+		"nudge.no_activity":    "Останнім часом ми не бачили твоїх PR у каналах рев’ю.",
+		"nudge.review":         "Маєш щось готове до рев’ю? Поділися в %s.",
+		"nudge.opt_out":        "Більше не нагадувати",
+		"nudge.opted_out":      "Ти більше не отримуватимеш цих нагадувань.",
+		"escalation.member":    "Привіт, %s! Ти в списку зомбі вже %d звітів поспіль, починаючи з %s. Тобі щось заважає?",
+		"escalation.manager":   "До відома: %s у списку зомбі вже %d звітів поспіль, починаючи з %s.",
+		"escalation.channel":   ":rotating_light: %s у списку зомбі вже %d звітів поспіль, починаючи з %s.",
+		"open_pr":              "Відкрити PR",
+		"part":                 "частина %d",
+		"streak.days":          "%d дн",
+		"streak.weeks":         "%d тиж",
+		"streak.months":        "%d міс",
+		"zombies":              "Зомбі",
+		"html.member":          "Учасник",
+		"html.prs":             "PR",
+		"html.links":           "Посилання",
+		"html.activity":        "Активність, %s – %s",
+		"html.generated":       "створено %s",
+		"heatmap.none":         "немає даних",
+		"heatmap.zombie":       "зомбі",
+		"heatmap.off_schedule": "поза графіком",
+		"heatmap.excused":      "звільнено",
+		"heatmap.prs":          "PR: %d",
+		"thread.parent":        ":zombie: Звіти про зомбі за %s",
+	},
+	Weekdays: [7]string{"Нд", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"},
+	DateTime: "02.01.2006 15:04",
+	Date:     "02.01.2006",
+	Day:      "%s %d",
+}
+
+var localeDE = &Locale{
+	Name: "de",
+	Messages: map[string]string{
+		"title":                "Zombie-Bericht (%s — %s bis %s)",
+		"mode.daily":           "Täglich",
+		"mode.weekly":          "Wöchentlich",
+		"mode.deep-scan":       "Tiefenscan",
+		"mode.monthly":         "Monatlich",
+		"mode.sprint":          "Sprint",
+		"mode.previous-week":   "Vorwoche",
+		"royal_members":        "Königliche Mitglieder",
+		"other_members":        "Weitere Mitglieder",
+		"below_expectation":    "Unter Erwartung",
+		"active_members":       "Aktive Mitglieder",
+		"no_zombies":           "Alle waren aktiv! Keine Zombies gefunden.",
+		"newly_zombie":         "Neue Zombies",
+		"chronic_zombie":       "Chronische Zombies",
+		"footer.active":        "Aktiv",
+		"footer.source":        "Quelle",
+		"footer.off_schedule":  "Nicht eingeplant",
+		"footer.channels":      "Kanäle",
+		"footer.excused":       "Entschuldigt",
+		"excused":              "Entschuldigt",
+		"excused.today":        "heute entschuldigt",
+		"excused.snooze":       "pausiert bis %s",
+		"excused.leave":        "abwesend",
+		"button.today":         "Heute entschuldigen",
+		"button.snooze":        "1 Woche pausieren",
+		"button.leave":         "Als abwesend markieren",
+		"button.clear":         "Entschuldigung aufheben",
+		"excused.cleared":      "Entschuldigung aufgehoben",
+		"nudge.greeting":       "Hallo %s! :wave:",
+		"nudge.last_activity":  "Deinen letzten PR in den Review-Kanälen haben wir am %s gesehen.",
+		"nudge.no_activity":    "In letzter Zeit haben wir keinen PR von dir in den Review-Kanälen gesehen.",
+		"nudge.review":         "Hast du etwas für ein Review? Teile es in %s.",
+		"nudge.opt_out":        "Keine Erinnerungen mehr",
+		"nudge.opted_out":      "Du bekommst diese Erinnerungen nicht mehr.",
+		"escalation.member":    "Hallo %s, du stehst seit %[3]s in %[2]d Berichten in Folge auf der Zombie-Liste. Blockiert dich etwas?",
+		"escalation.manager":   "Zur Info: %s steht seit %[3]s in %[2]d Berichten in Folge auf der Zombie-Liste.",
+		"escalation.channel":   ":rotating_light: %s steht seit %[3]s in %[2]d Berichten in Folge auf der Zombie-Liste.",
+		"open_pr":              "PR öffnen",
+		"part":                 "Teil %d",
+		"streak.days":          "%d T",
+		"streak.weeks":         "%d W",
+		"streak.months":        "%d M",
+		"zombies":              "Zombies",
+		"html.member":          "Mitglied",
+		"html.prs":             "PRs",
+		"html.links":           "Links",
+		"html.activity":        "Aktivität, %s – %s",
+		"html.generated":       "erstellt %s",
+		"heatmap.none":         "keine Daten",
+		"heatmap.zombie":       "Zombie",
+		"heatmap.off_schedule": "nicht eingeplant",
+		"heatmap.excused":      "entschuldigt",
+		"heatmap.prs":          "%d PRs",
+		"thread.parent":        ":zombie: Zombie-Berichte für %s",
+	},
+	Weekdays: [7]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+	DateTime: "02.01.2006 15:04",
+	Date:     "02.01.2006",
+	Day:      "%s %d.",
+}
+
+var locales = map[string]*Locale{"en": localeEN, "uk": localeUK, "de": localeDE}
+
+// LookupLocale returns the catalog for name; empty selects English.
+func LookupLocale(name string) (*Locale, error) {
+	if name == "" {
+		return localeEN, nil
+	}
+	if l, ok := locales[name]; ok {
+		return l, nil
+	}
+	names := make([]string, 0, len(locales))
+	for n := range locales {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("invalid locale %q: must be one of %s", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// logFlags registers --log-level and --log-format on fs. The returned
+// function installs the logger once fs is parsed; output of the log package,
+// e.g. from libraries, then goes through it at info level too.
+func logFlags(fs *flag.FlagSet) func() {
+	level := fs.String("log-level", "info", "Log level: debug, info, warn, or error")
+	format := fs.String("log-format", "text", "Log format: text or json")
+	return func() {
+		logger, err := newLogger(*level, *format)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		slog.SetDefault(logger)
+	}
+}
+
+func newLogger(level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: must be debug, info, warn, or error", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q: must be text or json", format)
+}
+
+// fatalf logs at error level and exits, like log.Fatalf.
+func fatalf(format string, args ...any) {
+	slog.Error(fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
@@ -3,26 +3,94 @@ package main
 import (
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 var (
-	validModes   = map[string]bool{"daily": true, "weekly": true, "deep-scan": true}
+	validModes = map[string]bool{
+		"daily": true, "weekly": true, "deep-scan": true,
+		"monthly": true, "sprint": true, "previous-week": true,
+	}
 	validSources = map[string]bool{"slack": true, "github": true, "both": true}
+	validFormats = map[string]bool{"text": true, "blocks": true, "html": true}
+	validOutputs = map[string]bool{"text": true, "json": true, "csv": true}
 )
 
+// commands are the subcommands, in the order usage lists them.
+var commands = []struct {
+	name, summary string
+	run           func(args []string)
+}{
+	{"report", "Scan for activity and send the report (the default)", runReport},
+	{"serve", "Run scheduled reports and the slash command over HTTP", runServe},
+	{"listen", "Answer the slash command, buttons and mentions over Socket Mode", runListen},
+	{"history", "List recorded runs", runHistory},
+	{"explain", "Show why one member is classified the way they are", runExplain},
+	{"nudges", "List nudge state and manage opt-outs", runNudges},
+	{"escalations", "List the escalation audit trail", runEscalations},
+	{"channels", "channels list: list channels and whether the bot is a member", runChannels},
+	{"users", "users find <name>: look up user IDs by name", runUsers},
+	{"config", "config validate | config init: check a config or create one interactively", runConfig},
+}
+
 func main() {
-	mode := flag.String("mode", "deep-scan", "Report mode: daily, weekly, or deep-scan")
-	source := flag.String("source", "both", "Data source: slack, github, or both")
-	days := flag.Int("days", 0, "Override time range in days (0 = use mode default)")
-	configPath := flag.String("config", "config.yaml", "Path to config file")
-	byDay := flag.Bool("by-day", true, "Group active member activity by day")
-	dryRun := flag.Bool("dry-run", false, "Print report to stdout instead of sending DM")
-	flag.Parse()
+	args := os.Args[1:]
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		// flags only, as before subcommands existed
+		runReport(args)
+		return
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			c.run(args[1:])
+			return
+		}
+	}
+	if args[0] == "help" {
+		usage(os.Stdout)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	usage(os.Stderr)
+	os.Exit(2)
+}
+
+func usage(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", filepath.Base(os.Args[0]))
+	for _, c := range commands {
+		_, _ = fmt.Fprintf(w, "  %-12s %s\n", c.name, c.summary)
+	}
+	_, _ = fmt.Fprintf(w, "\nRun %s <command> -h for the flags of a command.\n", filepath.Base(os.Args[0]))
+}
+
+// runReport implements the "report" subcommand: detect zombies for one
+// period and deliver or write the report.
+func runReport(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	mode := fs.String("mode", "deep-scan", "Report mode: daily, weekly, deep-scan, monthly, sprint, or previous-week")
+	source := fs.String("source", "both", "Data source: slack, github, or both")
+	days := fs.Int("days", 0, "Override time range in days (0 = use mode default)")
+	fromFlag := fs.String("from", "", "Period start as YYYY-MM-DD or RFC3339 (overrides mode)")
+	toFlag := fs.String("to", "", "Period end as YYYY-MM-DD (inclusive) or RFC3339 (overrides mode)")
+	configPath := fs.String("config", "config.yaml", "Path to config file")
+	byDay := fs.Bool("by-day", true, "Group active member activity by day")
+	dryRun := fs.Bool("dry-run", false, "Print report to stdout instead of sending DM")
+	format := fs.String("format", "text", "Report format: text or blocks (Block Kit) for destinations without their own format, or html written to stdout or --out-file")
+	output := fs.String("output", "text", "Output: text (send to Slack), json, or csv (write to stdout or --out-file)")
+	outFile := fs.String("out-file", "", "Write json/csv/html output to this file instead of stdout")
+	noCache := fs.Bool("no-cache", false, "Fetch all messages from Slack instead of using the local message cache")
+	metricsFile := fs.String("metrics-file", "", "Write run metrics to this file for the node_exporter textfile collector")
+	setupLog := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLog()
 
 	if !validModes[*mode] {
-		fmt.Fprintf(os.Stderr, "invalid mode %q: must be daily, weekly, or deep-scan\n", *mode)
+		fmt.Fprintf(os.Stderr, "invalid mode %q: must be daily, weekly, deep-scan, monthly, sprint, or previous-week\n", *mode)
 		os.Exit(1)
 	}
 	if !validSources[*source] {
@@ -30,32 +98,141 @@ func main() {
 		os.Exit(1)
 	}
 
+	if !validFormats[*format] {
+		fmt.Fprintf(os.Stderr, "invalid format %q: must be text, blocks, or html\n", *format)
+		os.Exit(1)
+	}
+
+	if !validOutputs[*output] {
+		fmt.Fprintf(os.Stderr, "invalid output %q: must be text, json, or csv\n", *output)
+		os.Exit(1)
+	}
+
 	cfg, err := LoadConfig(*configPath)
 	if err != nil {
-		log.Fatalf("config: %v", err)
+		fatalf("config: %v", err)
+	}
+
+	job := ReportJob{Mode: *mode, Source: *source, Period: PeriodOptions{Days: *days}, ByDay: *byDay, NoCache: *noCache}
+	if job.Period.From, err = ParseBoundary(*fromFlag, false); err != nil {
+		fatalf("period: %v", err)
+	}
+	if job.Period.To, err = ParseBoundary(*toFlag, true); err != nil {
+		fatalf("period: %v", err)
+	}
+
+	// Dry runs and file output work without the state store, just without
+	// history, streaks, excusals and the message cache.
+	store, err := OpenStore(cfg.StatePath)
+	if err != nil {
+		if !*dryRun && *output == "text" && *format != "html" {
+			fatalf("state: %v", err)
+		}
+		slog.Warn("state unavailable, reporting without history and cache", "err", err)
+		store = nil
+	} else {
+		defer func() { _ = store.Close() }()
 	}
 
 	client := NewSlackClient(cfg.SlackToken)
 
-	report, err := DetectZombies(client, cfg, *mode, *source, *days, *byDay)
+	// writeMetrics records the outcome of a run that is not a dry run in --metrics-file
+	start := time.Now()
+	writeMetrics := func(r *Report, err error) {
+		if *metricsFile == "" || *dryRun {
+			return
+		}
+		metrics.RecordRun(*mode, r, err, time.Since(start))
+		if err := metrics.WriteFile(*metricsFile); err != nil {
+			slog.Error("writing metrics file", "err", err)
+		}
+	}
+
+	report, err := runDetection(cfg, client, store, job)
 	if err != nil {
-		log.Fatalf("detect: %v", err)
+		writeMetrics(nil, err)
+		fatalf("%v", err)
 	}
 
-	messages := FormatReport(report)
+	if *output != "text" || *format == "html" {
+		// the heatmap shows runs of every mode, not only this one's history
+		var runs []RunRecord
+		if *output == "text" && store != nil {
+			if runs, err = store.Runs(heatmapStart(report), report.From); err != nil {
+				writeMetrics(report, err)
+				fatalf("history: %v", err)
+			}
+		}
+		err := writeOutput(*outFile, func(w io.Writer) error {
+			switch {
+			case *output == "json":
+				return WriteReportJSON(w, report)
+			case *output == "csv":
+				return WriteReportCSV(w, report)
+			default:
+				return WriteReportHTML(w, report, runs)
+			}
+		})
+		writeMetrics(report, err)
+		if err != nil {
+			fatalf("output: %v", err)
+		}
+		return
+	}
 
+	sinks, err := NewSinks(cfg, nil, client, store, *format)
+	if err != nil {
+		writeMetrics(report, err)
+		fatalf("delivery: %v", err)
+	}
 	if *dryRun {
-		for _, msg := range messages {
-			fmt.Print(msg)
+		for _, sink := range sinks {
+			if len(sinks) > 1 {
+				fmt.Printf("=== %s ===\n", sink.Name())
+			}
+			if err := sink.Preview(os.Stdout, report); err != nil {
+				fatalf("preview: %v", err)
+			}
+		}
+		if store == nil {
+			return
+		}
+		if err := previewNudges(os.Stdout, cfg, store, report); err != nil {
+			fatalf("preview: %v", err)
+		}
+		if err := previewEscalations(os.Stdout, cfg, client, store, report); err != nil {
+			fatalf("preview: %v", err)
 		}
 		return
 	}
 
-	for _, msg := range messages {
-		if err := client.SendDM(cfg.ReportRecipient, msg); err != nil {
-			log.Fatalf("send: %v", err)
-		}
+	sent, failed := deliverReport(report, sinks)
+	recordRun(store, report)
+	if len(failed) == 0 {
+		sendNudges(cfg, client, store, report)
+		sendEscalations(cfg, client, store, report)
+	}
+	if len(failed) > 0 {
+		err := fmt.Errorf("%d of %d destinations failed (%d messages sent)", len(failed), len(sinks), sent)
+		writeMetrics(report, err)
+		fatalf("send: %v", err)
 	}
+	writeMetrics(report, nil)
+	fmt.Printf("Report sent (%d messages).\n", sent)
+}
 
-	fmt.Printf("Report sent (%d messages).\n", len(messages))
+// writeOutput runs write against path, or stdout when path is empty.
+func writeOutput(path string, write func(io.Writer) error) error {
+	if path == "" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
 }
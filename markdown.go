@@ -0,0 +1,54 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	slackBold   = regexp.MustCompile(`\*([^*\n]+)\*`)
+	mentionLike = regexp.MustCompile(`(^|[^\p{L}\p{N}_/.@])@([\p{L}\p{N}_])`)
+)
+
+// emojiUnicode maps the emoji shortcodes used in reports for targets that do
+// not understand Slack shortcodes.
+var emojiUnicode = map[string]string{
+	":zombie:":                 "🧟",
+	":crown:":                  "👑",
+	":busts_in_silhouette:":    "👥",
+	":white_check_mark:":       "✅",
+	":hourglass_flowing_sand:": "⏳",
+	":new:":                    "🆕",
+	":skull:":                  "💀",
+	":palm_tree:":              "🌴",
+}
+
+// slackToMarkdown converts report mrkdwn to CommonMark-style markdown:
+// <url|label> links become [label](url) and *bold* becomes **bold**.
+// With unicodeEmoji, known shortcodes are replaced by their characters.
+func slackToMarkdown(text string, unicodeEmoji bool) string {
+	text = slackLink.ReplaceAllString(text, "[$2]($1)")
+	text = slackBold.ReplaceAllString(text, "**$1**")
+	if unicodeEmoji {
+		for code, char := range emojiUnicode {
+			text = strings.ReplaceAll(text, code, char)
+		}
+	}
+	return text
+}
+
+// escapeMentions puts a zero-width space after the @ of every @name, which
+// Slack leaves alone but Mattermost would turn into a mention that notifies
+// the member.
+func escapeMentions(text string) string {
+	return mentionLike.ReplaceAllString(text, "$1@\u200b$2")
+}
+
+// markdownUnits renders the report as markdown units for non-Slack targets.
+func markdownUnits(r *Report, unicodeEmoji bool) []string {
+	units := reportUnits(r)
+	for i, u := range units {
+		units[i] = slackToMarkdown(u, unicodeEmoji)
+	}
+	return units
+}
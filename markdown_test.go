@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestEscapeMentions(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"@alice", "@\u200balice"},
+		{"Zombies: @alice, @bob (3d)", "Zombies: @\u200balice, @\u200bbob (3d)"},
+		{"• @Олена", "• @\u200bОлена"},
+		{"@channel wake up", "@\u200bchannel wake up"},
+		{"mail ops@example.com", "mail ops@example.com"},
+		{"[1](https://medium.com/@alice/post)", "[1](https://medium.com/@alice/post)"},
+		{"no mentions", "no mentions"},
+	}
+	for _, tt := range tests {
+		if got := escapeMentions(tt.in); got != tt.want {
+			t.Errorf("escapeMentions(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTeamsPayloadSize(t *testing.T) {
+	r := &Report{
+		Mode: "weekly",
+		From: time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC),
+	}
+	// names full of characters that JSON escapes, so that the encoded
+	// payload is much larger than the text
+	for i := range 300 {
+		r.Active = append(r.Active, ActiveMember{
+			UserID:      fmt.Sprintf("U%d", i),
+			DisplayName: fmt.Sprintf("member%d%s", i, strings.Repeat("<&>", 10)),
+			Count:       1, Required: 1,
+			Messages: []MessageLink{{ChannelID: "C1", Timestamp: "1715000000.000100", PRURL: "https://github.com/org/repo/pull/1"}},
+		})
+	}
+	r.TotalCount = len(r.Active)
+
+	payloads, err := (&TeamsSink{dest: Destination{Detail: "full"}}).payloads(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payloads) < 2 {
+		t.Fatalf("got %d payloads, want the report split", len(payloads))
+	}
+	total := ""
+	for _, p := range payloads {
+		if len(p) > teamsMaxPayload {
+			t.Errorf("payload of %d bytes exceeds %d", len(p), teamsMaxPayload)
+		}
+		if !json.Valid(p) {
+			t.Errorf("invalid JSON payload")
+		}
+		total += string(p)
+	}
+	for _, name := range []string{`member0\u003c`, `member299\u003c`} {
+		if !strings.Contains(total, name) {
+			t.Errorf("%s missing from the cards", name)
+		}
+	}
+}
+
+func TestTeamsPayloadsEmptyTemplate(t *testing.T) {
+	for name, src := range map[string]string{"empty": "", "blank": "\n  \n\n", "blank units": "{{break}}  {{break}}\n"} {
+		t.Run(name, func(t *testing.T) {
+			r := &Report{
+				Mode:     "weekly",
+				From:     time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+				To:       time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC),
+				template: template.Must(template.New(name).Funcs(textTemplateFuncs).Parse(src)),
+			}
+			payloads, err := (&TeamsSink{dest: Destination{Detail: "full"}}).payloads(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(payloads) != 1 {
+				t.Fatalf("got %d payloads, want 1", len(payloads))
+			}
+			if !strings.Contains(string(payloads[0]), "Zombie Report (") {
+				t.Errorf("payload %s lacks the standard title", payloads[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Mattermost rejects posts longer than 16383 characters by default.
+const mattermostMaxLen = 16000
+
+// MattermostSink posts markdown reports through an incoming webhook, or
+// through the REST API with a bot token when one is configured.
+type MattermostSink struct {
+	dest Destination
+}
+
+func (s *MattermostSink) Name() string {
+	if s.dest.Token != "" {
+		return fmt.Sprintf("mattermost %s %s (%s)", s.dest.URL, strings.Join(s.dest.To, ","), s.dest.Detail)
+	}
+	return fmt.Sprintf("mattermost webhook %s (%s)", s.dest.URL, s.dest.Detail)
+}
+
+func (s *MattermostSink) posts(r *Report) []string {
+	units := markdownUnits(reportView(r, s.dest.Detail), false)
+	for i, u := range units {
+		units[i] = escapeMentions(u)
+	}
+	return packMessages(units, mattermostMaxLen)
+}
+
+func (s *MattermostSink) Deliver(r *Report) (int, error) {
+	sent := 0
+	for _, text := range s.posts(r) {
+		if s.dest.Token == "" {
+			body, err := json.Marshal(map[string]string{"text": text})
+			if err != nil {
+				return sent, err
+			}
+			if err := postWithRetry(s.dest.URL, map[string]string{"Content-Type": "application/json"}, body, s.dest.retries()); err != nil {
+				return sent, err
+			}
+			sent++
+			continue
+		}
+		headers := map[string]string{"Content-Type": "application/json", "Authorization": "Bearer " + s.dest.Token}
+		for _, channelID := range s.dest.To {
+			body, err := json.Marshal(map[string]string{"channel_id": channelID, "message": text})
+			if err != nil {
+				return sent, err
+			}
+			if err := postWithRetry(strings.TrimRight(s.dest.URL, "/")+"/api/v4/posts", headers, body, s.dest.retries()); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+func (s *MattermostSink) Preview(w io.Writer, r *Report) error {
+	for _, text := range s.posts(r) {
+		if _, err := fmt.Fprint(w, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
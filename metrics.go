@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metricDefs lists every exported metric with its type and help text.
+var metricDefs = map[string][2]string{
+	"zombie_detector_zombies":                        {"gauge", "Zombies in the last report."},
+	"zombie_detector_active_members":                 {"gauge", "Active members in the last report."},
+	"zombie_detector_below_expectation_members":      {"gauge", "Members below their expectation in the last report."},
+	"zombie_detector_tracked_members":                {"gauge", "Tracked members in the last report."},
+	"zombie_detector_channel_messages":               {"gauge", "Messages scanned per channel in the last scan."},
+	"zombie_detector_api_calls_total":                {"counter", "Slack and GitHub API requests."},
+	"zombie_detector_rate_limit_sleeps_total":        {"counter", "Waits after a rate-limited API request."},
+	"zombie_detector_rate_limit_sleep_seconds_total": {"counter", "Time spent waiting after rate-limited API requests."},
+	"zombie_detector_run_duration_seconds":           {"gauge", "Duration of the last report run."},
+	"zombie_detector_run_success":                    {"gauge", "Whether the last report run succeeded (1) or failed (0)."},
+	"zombie_detector_run_timestamp_seconds":          {"gauge", "Unix time the last report run finished."},
+}
+
+// Metrics holds the values of the exported metrics, keyed by name and then
+// by rendered label set.
+type Metrics struct {
+	mu     sync.Mutex
+	values map[string]map[string]float64
+}
+
+// metrics is the process-wide registry written by --metrics-addr and --metrics-file.
+var metrics = &Metrics{values: make(map[string]map[string]float64)}
+
+func (m *Metrics) set(name string, v float64, labels ...string) {
+	m.update(name, labels, func(float64) float64 { return v })
+}
+
+func (m *Metrics) add(name string, v float64, labels ...string) {
+	m.update(name, labels, func(old float64) float64 { return old + v })
+}
+
+// reset drops every label set of a metric, for gauges whose label sets
+// describe only the latest run.
+func (m *Metrics) reset(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, name)
+}
+
+func (m *Metrics) update(name string, labels []string, fn func(float64) float64) {
+	if _, ok := metricDefs[name]; !ok {
+		panic("undefined metric " + name)
+	}
+	key := formatLabels(labels)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values[name] == nil {
+		m.values[name] = make(map[string]float64)
+	}
+	m.values[name][key] = fn(m.values[name][key])
+}
+
+// formatLabels renders name/value pairs as a Prometheus label set.
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	var parts []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, labels[i], escape.Replace(labels[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// RecordRun sets the run metrics of one report run. job names the run: the
+// schedule in serve, the mode on the command line. r may be nil when the run
+// failed before detection finished.
+func (m *Metrics) RecordRun(job string, r *Report, err error, d time.Duration) {
+	success := 1.0
+	if err != nil {
+		success = 0
+	}
+	m.set("zombie_detector_run_success", success, "job", job)
+	m.set("zombie_detector_run_duration_seconds", d.Seconds(), "job", job)
+	m.set("zombie_detector_run_timestamp_seconds", float64(time.Now().Unix()), "job", job)
+	if r == nil {
+		return
+	}
+	m.set("zombie_detector_zombies", float64(len(r.RoyalZombies)+len(r.OtherZombies)), "job", job)
+	m.set("zombie_detector_active_members", float64(len(r.Active)), "job", job)
+	m.set("zombie_detector_below_expectation_members", float64(len(r.BelowExpectation)), "job", job)
+	m.set("zombie_detector_tracked_members", float64(r.TotalCount), "job", job)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.values))
+	for name := range m.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		def := metricDefs[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, def[1], name, def[0])
+		keys := make([]string, 0, len(m.values[name]))
+		for k := range m.values[name] {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "%s%s %g\n", name, k, m.values[name][k])
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// WriteFile writes the metrics for the node_exporter textfile collector,
+// replacing path atomically so the collector never reads a partial file.
+func (m *Metrics) WriteFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".metrics-*")
+	if err != nil {
+		return fmt.Errorf("writing metrics: %w", err)
+	}
+	if _, err := m.WriteTo(f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("writing metrics: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("writing metrics: %w", err)
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("writing metrics: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("writing metrics: %w", err)
+	}
+	return nil
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// serveMetrics serves /metrics on addr in the background for the life of the process.
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatalf("metrics: %v", err)
+		}
+	}()
+	slog.Info("serving metrics", "addr", addr, "path", "/metrics")
+}
+
+// countingTransport counts API requests by API and method.
+type countingTransport struct {
+	api string
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, "/api"), "/")
+	metrics.add("zombie_detector_api_calls_total", 1, "api", t.api, "method", method)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// apiHTTPClient returns an HTTP client whose requests count towards api.
+func apiHTTPClient(api string) *http.Client {
+	return &http.Client{Transport: countingTransport{api: api}}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMetricsWriteTo(t *testing.T) {
+	m := &Metrics{values: make(map[string]map[string]float64)}
+	m.set("zombie_detector_channel_messages", 12, "channel_id", "C1", "channel", `dev "ops"`)
+	m.set("zombie_detector_channel_messages", 3, "channel_id", "C2", "channel", "eng")
+	m.add("zombie_detector_api_calls_total", 1, "api", "slack", "method", "conversations.history")
+	m.add("zombie_detector_api_calls_total", 2, "api", "slack", "method", "conversations.history")
+
+	var b strings.Builder
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP zombie_detector_api_calls_total Slack and GitHub API requests.
+# TYPE zombie_detector_api_calls_total counter
+zombie_detector_api_calls_total{api="slack",method="conversations.history"} 3
+# HELP zombie_detector_channel_messages Messages scanned per channel in the last scan.
+# TYPE zombie_detector_channel_messages gauge
+zombie_detector_channel_messages{channel_id="C1",channel="dev \"ops\""} 12
+zombie_detector_channel_messages{channel_id="C2",channel="eng"} 3
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+
+	m.reset("zombie_detector_channel_messages")
+	m.set("zombie_detector_channel_messages", 5, "channel_id", "C2", "channel", "eng")
+	b.Reset()
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), `channel_id="C1"`) {
+		t.Errorf("channel C1 still exported after reset:\n%s", b.String())
+	}
+}
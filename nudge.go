@@ -0,0 +1,303 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+var bucketNudges = []byte("nudges")
+
+//go:embed templates/nudge.txt
+var defaultNudgeText string
+
+var defaultNudgeTemplate = template.Must(template.New("nudge.txt").Funcs(textTemplateFuncs).Parse(defaultNudgeText))
+
+const actionNudgeOptOut = "nudge_opt_out"
+
+// NudgeConfig is the nudge config section. Nudges are off unless enabled.
+type NudgeConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	EveryDays int    `yaml:"every_days"` // at most one nudge per member in this many days
+	Template  string `yaml:"template"`   // text/template file for the DM
+	Channel   string `yaml:"channel"`    // review channel to point to, by name or ID; defaults to the first channel
+
+	template *template.Template
+	channel  Channel
+}
+
+// validate resolves the review channel and loads the template of an enabled
+// nudge section.
+func (n *NudgeConfig) validate(cfg *Config, configPath string) error {
+	if !n.Enabled {
+		return nil
+	}
+	if n.EveryDays <= 0 {
+		n.EveryDays = 7
+	}
+	n.channel = cfg.Channels[0]
+	if n.Channel != "" {
+		ch := cfg.channelByName(strings.TrimPrefix(n.Channel, "#"))
+		for i := range cfg.Channels {
+			if cfg.Channels[i].ID == n.Channel {
+				ch = &cfg.Channels[i]
+			}
+		}
+		if ch == nil {
+			return fmt.Errorf("nudge.channel %q is not one of the configured channels", n.Channel)
+		}
+		n.channel = *ch
+	}
+	n.template = defaultNudgeTemplate
+	if n.Template != "" {
+		n.Template = relativeTo(configPath, n.Template)
+		src, err := os.ReadFile(n.Template)
+		if err != nil {
+			return fmt.Errorf("reading nudge template: %w", err)
+		}
+		if n.template, err = template.New(n.Template).Funcs(textTemplateFuncs).Parse(string(src)); err != nil {
+			return fmt.Errorf("parsing nudge template: %w", err)
+		}
+		if err := n.template.Execute(io.Discard, nudgeData{Locale: localeEN}); err != nil {
+			return fmt.Errorf("nudge template: %w", err)
+		}
+	}
+	return nil
+}
+
+// nudgeData is what nudge templates execute against.
+type nudgeData struct {
+	*Locale
+	UserID       string
+	Name         string
+	Mode         string
+	Streak       int       // zombie runs in a row, 0 without history
+	LastActivity time.Time // last PR link in the message cache before the period, zero if unknown
+	Channel      Channel   // review channel
+	ChannelLink  string    // review channel as a Slack mention, "<#C…>"
+}
+
+// NudgeState is what the state database remembers about nudging one member.
+type NudgeState struct {
+	UserID     string    `json:"user_id"`
+	LastNudged time.Time `json:"last_nudged,omitzero"`
+	OptedOut   bool      `json:"opted_out,omitempty"`
+}
+
+func (s *Store) NudgeState(userID string) (NudgeState, error) {
+	st := NudgeState{UserID: userID}
+	if _, err := s.get(bucketNudges, userID, &st); err != nil {
+		return st, fmt.Errorf("reading nudge state: %w", err)
+	}
+	return st, nil
+}
+
+func (s *Store) SaveNudgeState(st NudgeState) error {
+	if err := s.put(bucketNudges, st.UserID, st); err != nil {
+		return fmt.Errorf("saving nudge state: %w", err)
+	}
+	return nil
+}
+
+// NudgeStates returns every member's nudge state, in user ID order.
+func (s *Store) NudgeStates() ([]NudgeState, error) {
+	var out []NudgeState
+	err := s.scan(bucketNudges, "", "", func(_, v []byte) error {
+		var st NudgeState
+		if err := json.Unmarshal(v, &st); err != nil {
+			return err
+		}
+		out = append(out, st)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading nudge states: %w", err)
+	}
+	return out, nil
+}
+
+// Nudge is a planned DM to one zombie. Skip says why it is not sent.
+type Nudge struct {
+	UserID, Name string
+	Text         string
+	Skip         string
+}
+
+// planNudges renders a nudge for every zombie in the report, marking those
+// who opted out or were nudged too recently.
+func planNudges(cfg *Config, store *Store, r *Report, now time.Time) ([]Nudge, error) {
+	n := cfg.Nudge
+	zombies := append(append([]MemberReport(nil), r.RoyalZombies...), r.OtherZombies...)
+	var nudges []Nudge
+	var pending []string
+	for _, z := range zombies {
+		st, err := store.NudgeState(z.UserID)
+		if err != nil {
+			return nil, err
+		}
+		nudge := Nudge{UserID: z.UserID, Name: z.DisplayName}
+		switch {
+		case st.OptedOut:
+			nudge.Skip = "opted out"
+		case !st.LastNudged.IsZero() && now.Sub(st.LastNudged) < time.Duration(n.EveryDays)*24*time.Hour:
+			nudge.Skip = "nudged " + st.LastNudged.Local().Format("2006-01-02 15:04")
+		default:
+			pending = append(pending, z.UserID)
+		}
+		nudges = append(nudges, nudge)
+	}
+
+	// zombies have no PRs in the report period, so their last one is
+	// looked up in the message cache before it
+	lastPRs, err := store.LastPRs(pending, r.From)
+	if err != nil {
+		return nil, err
+	}
+	for i, z := range zombies {
+		if nudges[i].Skip != "" {
+			continue
+		}
+		var b strings.Builder
+		err = n.template.Execute(&b, nudgeData{
+			Locale: r.loc(), UserID: z.UserID, Name: z.DisplayName, Mode: r.Mode, Streak: z.Streak,
+			LastActivity: lastPRs[z.UserID], Channel: n.channel, ChannelLink: "<#" + n.channel.ID + ">",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("nudge template: %w", err)
+		}
+		nudges[i].Text = strings.TrimSpace(b.String())
+	}
+	return nudges, nil
+}
+
+// sendNudges DMs the report's zombies when nudging is enabled. Failures are
+// logged and do not stop the remaining nudges.
+func sendNudges(cfg *Config, client *SlackClient, store *Store, r *Report) {
+	if !cfg.Nudge.Enabled {
+		return
+	}
+	now := time.Now()
+	nudges, err := planNudges(cfg, store, r, now)
+	if err != nil {
+		slog.Error("nudge: planning", "err", err)
+		return
+	}
+	for _, n := range nudges {
+		if n.Skip != "" {
+			continue
+		}
+		if _, _, err := client.Post(n.UserID, nudgePost(n, r), ""); err != nil {
+			slog.Error("nudge: sending", "user", n.Name, "user_id", n.UserID, "err", err)
+			continue
+		}
+		slog.Info("nudged", "user", n.Name, "user_id", n.UserID)
+		st, err := store.NudgeState(n.UserID)
+		if err == nil {
+			st.LastNudged = now
+			err = store.SaveNudgeState(st)
+		}
+		if err != nil {
+			slog.Error("nudge: saving state", "user", n.Name, "user_id", n.UserID, "err", err)
+		}
+	}
+}
+
+// nudgePost adds an opt-out button to the nudge when the bot can receive clicks.
+func nudgePost(n Nudge, r *Report) SlackPost {
+	p := SlackPost{Text: n.Text}
+	if r.Interactive {
+		button := slack.NewButtonBlockElement(actionNudgeOptOut, n.UserID, plainText(r.loc().T("nudge.opt_out")))
+		p.Blocks = []slack.Block{mrkdwnSection(truncate(n.Text, maxSectionText)), slack.NewActionBlock("", button)}
+	}
+	return p
+}
+
+// previewNudges writes who would be nudged and with what text.
+func previewNudges(w io.Writer, cfg *Config, store *Store, r *Report) error {
+	if !cfg.Nudge.Enabled {
+		return nil
+	}
+	nudges, err := planNudges(cfg, store, r, time.Now())
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w, "=== nudges ===")
+	if len(nudges) == 0 {
+		_, _ = fmt.Fprintln(w, "No zombies to nudge.")
+	}
+	for _, n := range nudges {
+		if n.Skip != "" {
+			_, _ = fmt.Fprintf(w, "@%s: skipped (%s)\n", n.Name, n.Skip)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "@%s:\n%s\n\n", n.Name, n.Text)
+	}
+	return nil
+}
+
+// runNudges implements the "nudges" subcommand, listing nudge state and
+// opting members out of nudges or back in.
+func runNudges(args []string) {
+	fs := flag.NewFlagSet("nudges", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "Path to config file")
+	optOut := fs.String("opt-out", "", "Stop nudging this user ID")
+	optIn := fs.String("opt-in", "", "Resume nudging this user ID")
+	setupLog := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLog()
+
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		fatalf("config: %v", err)
+	}
+	store, err := OpenStore(cfg.StatePath)
+	if err != nil {
+		fatalf("nudges: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if *optOut != "" || *optIn != "" {
+		for _, change := range []struct {
+			userID   string
+			optedOut bool
+		}{{*optOut, true}, {*optIn, false}} {
+			if change.userID == "" {
+				continue
+			}
+			st, err := store.NudgeState(change.userID)
+			if err != nil {
+				fatalf("nudges: %v", err)
+			}
+			st.OptedOut = change.optedOut
+			if err := store.SaveNudgeState(st); err != nil {
+				fatalf("nudges: %v", err)
+			}
+		}
+		return
+	}
+
+	states, err := store.NudgeStates()
+	if err != nil {
+		fatalf("nudges: %v", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "USER\tLAST NUDGED\tOPTED OUT")
+	for _, st := range states {
+		last := "-"
+		if !st.LastNudged.IsZero() {
+			last = st.LastNudged.Local().Format("2006-01-02 15:04")
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%t\n", st.UserID, last, st.OptedOut)
+	}
+	_ = w.Flush()
+}
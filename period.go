@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// PeriodOptions carries everything besides the mode that affects the report window.
+type PeriodOptions struct {
+	Days   int       // rolling-window override for daily, weekly and deep-scan
+	From   time.Time // explicit start, overrides the mode's start when set
+	To     time.Time // explicit end, overrides the mode's end when set
+	Sprint Sprint
+	Now    time.Time
+}
+
+// ResolvePeriod turns a report mode plus overrides into a [from, to) window.
+//
+// Rolling modes (daily, weekly, deep-scan) end now and start at midnight N days
+// back. Calendar modes end at the start of the current period: previous-week is
+// last Monday–Sunday, monthly is the previous calendar month and sprint is the
+// most recently completed sprint.
+func ResolvePeriod(mode string, opts PeriodOptions) (from, to time.Time, err error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if opts.Days > 0 && (mode == "previous-week" || mode == "monthly" || mode == "sprint") {
+		return time.Time{}, time.Time{}, fmt.Errorf("--days does not apply to %s mode; use --from/--to", mode)
+	}
+
+	switch mode {
+	case "daily", "deep-scan", "weekly":
+		days := 1
+		if mode == "weekly" {
+			days = 7
+		}
+		if opts.Days > 0 {
+			days = opts.Days
+		}
+		to = now
+		from = startOfDay(now.AddDate(0, 0, -days))
+	case "previous-week":
+		offset := (int(now.Weekday()) + 6) % 7 // days since Monday
+		to = startOfDay(now.AddDate(0, 0, -offset))
+		from = to.AddDate(0, 0, -7)
+	case "monthly":
+		to = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		from = to.AddDate(0, -1, 0)
+	case "sprint":
+		from, to, err = lastSprint(opts.Sprint, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown mode %q", mode)
+	}
+
+	if !opts.From.IsZero() {
+		from = opts.From
+	}
+	if !opts.To.IsZero() {
+		to = opts.To
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("period start %s is not before end %s",
+			from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	return from, to, nil
+}
+
+// lastSprint returns the most recent sprint that has fully ended before now.
+func lastSprint(s Sprint, now time.Time) (from, to time.Time, err error) {
+	if s.Start == "" || s.LengthDays <= 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("sprint mode requires sprint.start and sprint.length_days in config")
+	}
+	start, err := time.ParseInLocation(dateLayout, s.Start, now.Location())
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("parsing sprint.start: %w", err)
+	}
+	elapsed := int(math.Round(startOfDay(now).Sub(start).Hours() / 24))
+	if elapsed < s.LengthDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("no sprint has completed since %s", s.Start)
+	}
+	completed := elapsed / s.LengthDays
+	from = start.AddDate(0, 0, (completed-1)*s.LengthDays)
+	to = from.AddDate(0, 0, s.LengthDays)
+	return from, to, nil
+}
+
+// ParseBoundary parses a --from/--to value given as a date or RFC3339 timestamp.
+// A bare date used as an end boundary includes that whole day.
+func ParseBoundary(value string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(dateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use YYYY-MM-DD or RFC3339", value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
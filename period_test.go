@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolvePeriod(t *testing.T) {
+	// Wednesday afternoon
+	now := time.Date(2024, 5, 15, 14, 30, 0, 0, time.UTC)
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+	sprint := Sprint{Start: "2024-04-01", LengthDays: 14}
+
+	tests := []struct {
+		name     string
+		mode     string
+		opts     PeriodOptions
+		from, to time.Time
+		wantErr  bool
+	}{
+		{name: "daily", mode: "daily", from: day(5, 14), to: now},
+		{name: "weekly", mode: "weekly", from: day(5, 8), to: now},
+		{name: "deep-scan with days", mode: "deep-scan", opts: PeriodOptions{Days: 30}, from: day(4, 15), to: now},
+		{name: "previous-week", mode: "previous-week", from: day(5, 6), to: day(5, 13)},
+		{name: "monthly", mode: "monthly", from: day(4, 1), to: day(5, 1)},
+		{name: "sprint", mode: "sprint", opts: PeriodOptions{Sprint: sprint}, from: day(4, 29), to: day(5, 13)},
+		{name: "explicit from and to", mode: "weekly", opts: PeriodOptions{From: day(5, 1), To: day(5, 3)}, from: day(5, 1), to: day(5, 3)},
+		{name: "from after to", mode: "daily", opts: PeriodOptions{From: day(5, 16)}, wantErr: true},
+		{name: "unknown mode", mode: "yearly", wantErr: true},
+		{name: "sprint without config", mode: "sprint", wantErr: true},
+		{name: "days with monthly", mode: "monthly", opts: PeriodOptions{Days: 3}, wantErr: true},
+		{name: "days with previous-week", mode: "previous-week", opts: PeriodOptions{Days: 3}, wantErr: true},
+		{name: "days with sprint", mode: "sprint", opts: PeriodOptions{Days: 3, Sprint: sprint}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Now = now
+			from, to, err := ResolvePeriod(tt.mode, tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got [%s, %s), want error", from, to)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !from.Equal(tt.from) || !to.Equal(tt.to) {
+				t.Errorf("got [%s, %s), want [%s, %s)", from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestParseBoundary(t *testing.T) {
+	tests := []struct {
+		value   string
+		end     bool
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "", want: time.Time{}},
+		{value: "2024-05-01", want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+		{value: "2024-05-01", end: true, want: time.Date(2024, 5, 2, 0, 0, 0, 0, time.Local)},
+		{value: "2024-05-01T10:00:00Z", end: true, want: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{value: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBoundary(tt.value, tt.end)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBoundary(%q, %v) error = %v", tt.value, tt.end, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseBoundary(%q, %v) = %s, want %s", tt.value, tt.end, got, tt.want)
+		}
+	}
+}
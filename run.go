@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ReportJob describes one report run, whether started from the command line
+// or by the scheduler.
+type ReportJob struct {
+	Mode, Source string
+	Period       PeriodOptions // Sprint is taken from the config
+	ByDay        bool
+	NoCache      bool
+	Trace        *DetectTrace
+}
+
+// runDetection resolves the job's period, scans for activity, and annotates
+// the report with history. Without a store it reports without the cache,
+// excusals and history.
+func runDetection(cfg *Config, client *SlackClient, store *Store, job ReportJob) (*Report, error) {
+	job.Period.Sprint = cfg.Sprint
+	from, to, err := ResolvePeriod(job.Mode, job.Period)
+	if err != nil {
+		return nil, fmt.Errorf("period: %w", err)
+	}
+
+	opts := DetectOptions{Mode: job.Mode, Source: job.Source, From: from, To: to, ByDay: job.ByDay, Trace: job.Trace}
+	if store != nil {
+		if !job.NoCache {
+			opts.Cache = NewMessageCache(store, time.Duration(cfg.CacheRevalidateHours)*time.Hour)
+		}
+		if opts.Excusals, err = store.Excusals(); err != nil {
+			return nil, err
+		}
+	}
+	report, err := DetectZombies(client, cfg, opts)
+	if err != nil {
+		return nil, fmt.Errorf("detect: %w", err)
+	}
+	if store == nil {
+		return report, nil
+	}
+
+	runs, err := store.RecentRuns(report.Mode, report.From, historyEnough(report))
+	if err != nil {
+		return nil, fmt.Errorf("history: %w", err)
+	}
+	AnnotateHistory(report, runs, cfg.ChronicStreak)
+	return report, nil
+}
+
+// deliverReport sends the report to every sink. It keeps going past failed
+// sinks, logging them, and returns them.
+func deliverReport(report *Report, sinks []Sink) (sent int, failed []Sink) {
+	for _, sink := range sinks {
+		n, err := sink.Deliver(report)
+		sent += n
+		if err != nil {
+			slog.Error("delivery failed", "sink", sink.Name(), "messages", n, "err", err)
+			failed = append(failed, sink)
+			continue
+		}
+		slog.Info("delivered report", "sink", sink.Name(), "messages", n)
+	}
+	return sent, failed
+}
+
+// recordRun adds a delivered report to the history.
+func recordRun(store *Store, report *Report) {
+	if err := store.SaveRun(NewRunRecord(report)); err != nil {
+		slog.Error("saving history", "err", err)
+	}
+}
+
+// reportRunner runs reports for long-lived modes, one at a time so that
+// they share Slack rate limits and the state database politely.
+type reportRunner struct {
+	cfg    *Config
+	client *SlackClient
+	store  *Store
+	mu     sync.Mutex
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var bucketSchedules = []byte("schedules")
+
+// Schedule is one entry of the schedules config section, run by "serve".
+type Schedule struct {
+	Name     string        `yaml:"name"`
+	Cron     string        `yaml:"cron"`     // five-field cron expression
+	Timezone string        `yaml:"timezone"` // IANA name; defaults to the local zone
+	Mode     string        `yaml:"mode"`
+	Source   string        `yaml:"source"` // defaults to both
+	Days     int           `yaml:"days"`
+	Format   string        `yaml:"format"`   // text (default) or blocks
+	Delivery []Destination `yaml:"delivery"` // defaults to the top-level delivery
+
+	cron *CronSchedule
+	loc  *time.Location
+}
+
+func (s *Schedule) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	var err error
+	if s.cron, err = ParseCron(s.Cron); err != nil {
+		return err
+	}
+	if s.loc, err = time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("timezone: %w", err)
+	}
+	if !validModes[s.Mode] {
+		return fmt.Errorf("invalid mode %q", s.Mode)
+	}
+	if s.Days > 0 && s.Mode != "daily" && s.Mode != "weekly" && s.Mode != "deep-scan" {
+		return fmt.Errorf("days does not apply to %s mode", s.Mode)
+	}
+	if s.Source == "" {
+		s.Source = "both"
+	}
+	if !validSources[s.Source] {
+		return fmt.Errorf("invalid source %q", s.Source)
+	}
+	switch s.Format {
+	case "":
+		s.Format = "text"
+	case "text", "blocks":
+	default:
+		return fmt.Errorf("invalid format %q: must be text or blocks", s.Format)
+	}
+	return nil
+}
+
+// ServeConfig tunes the "serve" daemon.
+type ServeConfig struct {
+	Addr              string `yaml:"addr"`                // status endpoint, default ":8080"
+	JitterSeconds     *int   `yaml:"jitter_seconds"`      // random delay added to each run, default 60, 0 for none
+	Retries           *int   `yaml:"retries"`             // attempts after a failed run, default 3, 0 for none
+	RetryDelaySeconds int    `yaml:"retry_delay_seconds"` // first retry delay, doubled each time, default 60
+}
+
+// jitter returns the longest random delay added to each scheduled run.
+func (c *ServeConfig) jitter() time.Duration {
+	if c.JitterSeconds == nil {
+		return 60 * time.Second
+	}
+	return time.Duration(*c.JitterSeconds) * time.Second
+}
+
+// retries returns how often a failed scheduled run is retried.
+func (c *ServeConfig) retries() int {
+	if c.Retries == nil {
+		return 3
+	}
+	return *c.Retries
+}
+
+func (c *ServeConfig) validate() error {
+	if c.JitterSeconds != nil && *c.JitterSeconds < 0 {
+		return fmt.Errorf("serve.jitter_seconds must not be negative")
+	}
+	if c.Retries != nil && *c.Retries < 0 {
+		return fmt.Errorf("serve.retries must not be negative")
+	}
+	if c.Addr == "" {
+		c.Addr = ":8080"
+	}
+	if c.RetryDelaySeconds <= 0 {
+		c.RetryDelaySeconds = 60
+	}
+	return nil
+}
+
+// ScheduleStatus is the state of one schedule as reported on /status and
+// kept across restarts.
+type ScheduleStatus struct {
+	Name        string    `json:"name"`
+	Cron        string    `json:"cron"`
+	Timezone    string    `json:"timezone"`
+	Next        time.Time `json:"next,omitzero"`
+	State       string    `json:"state"` // idle, running, or retrying
+	LastStart   time.Time `json:"last_start,omitzero"`
+	LastFinish  time.Time `json:"last_finish,omitzero"`
+	LastResult  string    `json:"last_result,omitempty"` // ok or failed
+	LastError   string    `json:"last_error,omitempty"`
+	LastSent    int       `json:"last_sent"`
+	Attempts    int       `json:"attempts"`
+	LastSuccess time.Time `json:"last_success,omitzero"`
+}
+
+// runServe implements the "serve" subcommand: it runs every configured
+// schedule until interrupted and serves their status, and the slash command
+// when configured, over HTTP.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "Path to config file")
+	addr := fs.String("addr", "", "Status listen address (overrides serve.addr)")
+	metricsAddr := fs.String("metrics-addr", "", "Serve Prometheus metrics on this address, e.g. :9090; may equal the status address")
+	setupLog := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLog()
+
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		fatalf("config: %v", err)
+	}
+	if len(cfg.Schedules) == 0 && cfg.Commands.SigningSecret == "" {
+		fatalf("config: serve needs schedules or commands.signing_secret")
+	}
+	if *addr != "" {
+		cfg.Serve.Addr = *addr
+	}
+
+	store, err := OpenStore(cfg.StatePath)
+	if err != nil {
+		fatalf("state: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	runner := &reportRunner{cfg: cfg, client: NewSlackClient(cfg.SlackToken), store: store}
+	s := &scheduler{reportRunner: runner, status: make(map[string]*ScheduleStatus)}
+	for _, sched := range cfg.Schedules {
+		st := &ScheduleStatus{}
+		if _, err := store.get(bucketSchedules, sched.Name, st); err != nil {
+			slog.Error("reading schedule status", "schedule", sched.Name, "err", err)
+		}
+		st.Name, st.Cron, st.Timezone, st.State = sched.Name, sched.Cron, sched.loc.String(), "idle"
+		s.status[sched.Name] = st
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	if cfg.Commands.SigningSecret != "" {
+		bot := &botHandler{runner}
+		mux.HandleFunc("POST /slack/commands", bot.serveCommand)
+		mux.HandleFunc("POST /slack/interactions", bot.serveInteraction)
+		mux.HandleFunc("POST /slack/events", bot.serveEvents)
+	}
+	switch *metricsAddr {
+	case "":
+	case cfg.Serve.Addr:
+		mux.Handle("GET /metrics", metrics)
+	default:
+		serveMetrics(*metricsAddr)
+	}
+	srv := &http.Server{Addr: cfg.Serve.Addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatalf("serve: %v", err)
+		}
+	}()
+	slog.Info("serving", "schedules", len(cfg.Schedules), "addr", cfg.Serve.Addr)
+
+	var wg sync.WaitGroup
+	for i := range cfg.Schedules {
+		wg.Add(1)
+		go func(sched *Schedule) {
+			defer wg.Done()
+			s.loop(ctx, sched)
+		}(&cfg.Schedules[i])
+	}
+
+	<-ctx.Done()
+	slog.Info("shutting down, waiting for running reports")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = srv.Shutdown(shutdownCtx)
+	wg.Wait()
+}
+
+type scheduler struct {
+	*reportRunner
+
+	statusMu sync.Mutex
+	status   map[string]*ScheduleStatus
+}
+
+// loop runs sched at each of its times, plus jitter, until ctx is done.
+func (s *scheduler) loop(ctx context.Context, sched *Schedule) {
+	for {
+		next := sched.cron.Next(time.Now().In(sched.loc))
+		if next.IsZero() {
+			slog.Error("schedule never matches", "schedule", sched.Name, "cron", sched.Cron)
+			return
+		}
+		s.update(sched.Name, func(st *ScheduleStatus) { st.Next = next })
+
+		delay := time.Until(next)
+		if j := s.cfg.Serve.jitter(); j > 0 {
+			delay += rand.N(j)
+		}
+		if !sleep(ctx, delay) {
+			return
+		}
+		s.run(ctx, sched)
+	}
+}
+
+// run executes one scheduled report, retrying with exponential backoff.
+// Retries deliver the same report, and only to destinations that have not
+// received it yet.
+func (s *scheduler) run(ctx context.Context, sched *Schedule) {
+	start := time.Now()
+	s.update(sched.Name, func(st *ScheduleStatus) {
+		st.State, st.LastStart, st.Attempts = "running", start, 0
+	})
+
+	var job scheduledRun
+	var sent int
+	var err error
+	backoff := time.Duration(s.cfg.Serve.RetryDelaySeconds) * time.Second
+	for attempt := 0; attempt <= s.cfg.Serve.retries(); attempt++ {
+		if attempt > 0 {
+			slog.Warn("schedule: attempt failed, retrying", "schedule", sched.Name, "attempt", attempt, "backoff", backoff, "err", err)
+			s.update(sched.Name, func(st *ScheduleStatus) { st.State = "retrying" })
+			if !sleep(ctx, backoff) {
+				break
+			}
+			backoff *= 2
+		}
+		s.update(sched.Name, func(st *ScheduleStatus) { st.State, st.Attempts = "running", attempt+1 })
+
+		var n int
+		n, err = s.runOnce(sched, &job)
+		sent += n
+		if err == nil {
+			break
+		}
+	}
+
+	s.update(sched.Name, func(st *ScheduleStatus) {
+		st.State, st.LastFinish, st.LastSent = "idle", time.Now(), sent
+		if err != nil {
+			st.LastResult, st.LastError = "failed", err.Error()
+			return
+		}
+		st.LastResult, st.LastError, st.LastSuccess = "ok", "", start
+	})
+	if err != nil {
+		slog.Error("schedule: giving up", "schedule", sched.Name, "err", err)
+	} else {
+		slog.Info("schedule: report sent", "schedule", sched.Name, "messages", sent)
+	}
+}
+
+// scheduledRun is one scheduled report across its delivery attempts.
+type scheduledRun struct {
+	report  *Report // detected once, then delivered again on retries
+	pending []Sink  // destinations that still need the report; nil before the first attempt
+}
+
+// runOnce generates the report unless an earlier attempt did, and delivers it
+// to the destinations still pending, leaving the ones that failed in run.
+func (s *scheduler) runOnce(sched *Schedule, run *scheduledRun) (sent int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := time.Now()
+	defer func() { metrics.RecordRun(sched.Name, run.report, err, time.Since(start)) }()
+
+	if run.pending == nil {
+		if run.pending, err = NewSinks(s.cfg, sched.Delivery, s.client, s.store, sched.Format); err != nil {
+			return 0, fmt.Errorf("delivery: %w", err)
+		}
+	}
+	fresh := run.report == nil
+	if fresh {
+		period := PeriodOptions{Days: sched.Days, Now: time.Now().In(sched.loc)}
+		job := ReportJob{Mode: sched.Mode, Source: sched.Source, Period: period, ByDay: true}
+		if run.report, err = runDetection(s.cfg, s.client, s.store, job); err != nil {
+			return 0, err
+		}
+	}
+	total := len(run.pending)
+	sent, run.pending = deliverReport(run.report, run.pending)
+	if fresh {
+		recordRun(s.store, run.report)
+	}
+	if len(run.pending) > 0 {
+		return sent, fmt.Errorf("%d of %d destinations failed", len(run.pending), total)
+	}
+	// nudges and escalations wait until every destination has the report
+	sendNudges(s.cfg, s.client, s.store, run.report)
+	sendEscalations(s.cfg, s.client, s.store, run.report)
+	return sent, nil
+}
+
+// update applies fn to a schedule's status and persists it.
+func (s *scheduler) update(name string, fn func(*ScheduleStatus)) {
+	s.statusMu.Lock()
+	st := s.status[name]
+	fn(st)
+	snapshot := *st
+	s.statusMu.Unlock()
+	if err := s.store.put(bucketSchedules, name, snapshot); err != nil {
+		slog.Error("saving schedule status", "schedule", name, "err", err)
+	}
+}
+
+func (s *scheduler) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	s.statusMu.Lock()
+	out := make([]ScheduleStatus, 0, len(s.cfg.Schedules))
+	for _, sched := range s.cfg.Schedules {
+		out = append(out, *s.status[sched.Name])
+	}
+	s.statusMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(out)
+}
+
+// sleep waits for d and reports whether ctx is still live.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServeConfigDefaults(t *testing.T) {
+	five, negative := 5, -1
+	tests := []struct {
+		name            string
+		jitter, retries *int
+		wantJitter      time.Duration
+		wantRetries     int
+		wantErr         bool
+	}{
+		{name: "defaults", wantJitter: time.Minute, wantRetries: 3},
+		{name: "zero turns both off", jitter: new(int), retries: new(int), wantJitter: 0, wantRetries: 0},
+		{name: "explicit", jitter: &five, retries: &five, wantJitter: 5 * time.Second, wantRetries: 5},
+		{name: "negative jitter", jitter: &negative, wantErr: true},
+		{name: "negative retries", retries: &negative, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ServeConfig{JitterSeconds: tt.jitter, Retries: tt.retries}
+			err := c.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if c.jitter() != tt.wantJitter {
+				t.Errorf("jitter() = %s, want %s", c.jitter(), tt.wantJitter)
+			}
+			if c.retries() != tt.wantRetries {
+				t.Errorf("retries() = %d, want %d", c.retries(), tt.wantRetries)
+			}
+			if c.Addr != ":8080" || c.RetryDelaySeconds != 60 {
+				t.Errorf("addr %q, retry delay %d, want the defaults", c.Addr, c.RetryDelaySeconds)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+//go:embed config.yaml.example
+var exampleConfig string
+
+// runConfig implements "config validate" and "config init".
+func runConfig(args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "validate":
+			runConfigValidate(args[1:])
+			return
+		case "init":
+			runConfigInit(args[1:])
+			return
+		}
+	}
+	fatalf("usage: config validate [--config=config.yaml] [--slack] | config init [--config=config.yaml] [--force]")
+}
+
+// runConfigValidate loads a config the way every command does and, with
+// --slack, checks the token, channels and recipient against Slack.
+func runConfigValidate(args []string) {
+	flags := flag.NewFlagSet("config validate", flag.ExitOnError)
+	configPath := flags.String("config", "config.yaml", "Path to config file")
+	online := flags.Bool("slack", false, "Also check the token, channels and report recipient against Slack")
+	setupLog := logFlags(flags)
+	_ = flags.Parse(args)
+	setupLog()
+
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		fatalf("%s: %v", *configPath, err)
+	}
+	fmt.Printf("%s is valid: %d channels, %d delivery destinations, %d schedules, %d escalation rules\n",
+		*configPath, len(cfg.Channels), len(cfg.Delivery), len(cfg.Schedules), len(cfg.Escalations))
+	if !*online {
+		return
+	}
+
+	client := NewSlackClient(cfg.SlackToken)
+	auth, err := client.AuthTest()
+	if err != nil {
+		fatalf("slack_token: %v", err)
+	}
+	fmt.Printf("slack_token: bot @%s in %s\n", auth.User, auth.Team)
+	problems := 0
+	for _, c := range cfg.Channels {
+		ch, err := client.ChannelInfo(c.ID)
+		switch {
+		case err != nil:
+			fmt.Printf("channel #%s (%s): %v\n", c.Name, c.ID, err)
+			problems++
+		case !ch.IsMember:
+			fmt.Printf("channel #%s (%s): the bot is not a member; invite it with /invite @%s\n", c.Name, c.ID, auth.User)
+			problems++
+		case ch.Name != c.Name:
+			fmt.Printf("channel #%s (%s): ok, but Slack calls it #%s\n", c.Name, c.ID, ch.Name)
+		default:
+			fmt.Printf("channel #%s (%s): ok\n", c.Name, c.ID)
+		}
+	}
+	if cfg.ReportRecipient != "" {
+		if name, err := client.GetUserDisplayName(cfg.ReportRecipient); err != nil {
+			fmt.Printf("report_recipient %s: %v\n", cfg.ReportRecipient, err)
+			problems++
+		} else {
+			fmt.Printf("report_recipient %s: @%s\n", cfg.ReportRecipient, name)
+		}
+	}
+	if problems > 0 {
+		fatalf("%s: %d problems found", *configPath, problems)
+	}
+}
+
+// runConfigInit asks for a token, channels and recipient, looking up their
+// IDs in Slack, and writes a config based on config.yaml.example.
+func runConfigInit(args []string) {
+	flags := flag.NewFlagSet("config init", flag.ExitOnError)
+	configPath := flags.String("config", "config.yaml", "Config file to write")
+	force := flags.Bool("force", false, "Overwrite an existing config file")
+	setupLog := logFlags(flags)
+	_ = flags.Parse(args)
+	setupLog()
+
+	if _, err := os.Stat(*configPath); err == nil && !*force {
+		fatalf("%s already exists; pass --force to overwrite it", *configPath)
+	}
+	p := &prompter{in: bufio.NewScanner(os.Stdin), out: os.Stdout}
+
+	var answers initAnswers
+	var client *SlackClient
+	for client == nil {
+		answers.Token = p.ask("Slack bot token (xoxb-...)", os.Getenv("SLACK_TOKEN"))
+		c := NewSlackClient(answers.Token)
+		auth, err := c.AuthTest()
+		if err != nil {
+			p.say("  %v", err)
+			continue
+		}
+		p.say("  Connected to %s as @%s.", auth.Team, auth.User)
+		client = c
+	}
+
+	channels, err := client.FetchAllChannels()
+	if err != nil {
+		fatalf("config init: %v", err)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+	var joined []slack.Channel
+	for _, ch := range channels {
+		if ch.IsMember {
+			joined = append(joined, ch)
+		}
+	}
+	if len(joined) == 0 {
+		p.say("The bot is not in any channel yet. Invite it with /invite in the channel to monitor, then run config init again.")
+		os.Exit(1)
+	}
+	p.say("Channels the bot is in:")
+	for i, ch := range joined {
+		p.say("  %d. #%s", i+1, ch.Name)
+	}
+	for answers.Channels == nil {
+		picked, err := pickChannels(joined, p.ask("Channels to monitor (numbers or names, comma-separated; the first one's members are tracked)", "1"))
+		if err != nil {
+			p.say("  %v", err)
+			continue
+		}
+		answers.Channels = picked
+	}
+
+	users, err := client.FetchUsers()
+	if err != nil {
+		fatalf("config init: %v", err)
+	}
+	for answers.Recipient == "" {
+		query := p.ask("Who receives the report? (your display name or user ID)", "")
+		matches := findUsers(users, query)
+		if exact := exactUsers(matches, query); len(exact) == 1 {
+			matches = exact
+		}
+		switch len(matches) {
+		case 0:
+			p.say("  No user matches %q.", query)
+		case 1:
+			answers.Recipient = matches[0].ID
+			p.say("  @%s (%s)", displayName(&matches[0]), matches[0].ID)
+		default:
+			p.say("  %d users match; be more specific or use the ID:", len(matches))
+			for _, u := range matches[:min(len(matches), 10)] {
+				p.say("    %s  @%s  %s", u.ID, displayName(&u), u.RealName)
+			}
+		}
+	}
+
+	for answers.Locale == "" {
+		locale := p.ask("Report language (en, uk, de)", "en")
+		if _, err := LookupLocale(locale); err != nil {
+			p.say("  %v", err)
+			continue
+		}
+		answers.Locale = locale
+	}
+
+	if err := os.WriteFile(*configPath, []byte(renderConfig(exampleConfig, answers)), 0o600); err != nil {
+		fatalf("config init: %v", err)
+	}
+	if _, err := LoadConfig(*configPath); err != nil {
+		fatalf("config init: wrote %s, but it does not load: %v", *configPath, err)
+	}
+	p.say("\nWrote %s. Review the whitelist, royal_members and expectations examples in it, then try:", *configPath)
+	p.say("  %s report --mode=daily --dry-run --config=%s", os.Args[0], *configPath)
+}
+
+type initAnswers struct {
+	Token     string
+	Channels  []Channel
+	Recipient string
+	Locale    string
+}
+
+// renderConfig fills the answers into the example config, keeping its
+// comments and remaining example entries.
+func renderConfig(example string, a initAnswers) string {
+	var out []string
+	inChannels := false
+	for _, line := range strings.Split(example, "\n") {
+		if inChannels {
+			if strings.HasPrefix(line, " ") {
+				continue
+			}
+			inChannels = false
+		}
+		switch {
+		case strings.HasPrefix(line, "slack_token:"):
+			out = append(out, "slack_token: "+strconv.Quote(a.Token))
+		case line == "channels:":
+			out = append(out, line)
+			for _, ch := range a.Channels {
+				out = append(out, "  - id: "+strconv.Quote(ch.ID), "    name: "+strconv.Quote(ch.Name))
+			}
+			inChannels = true
+		case strings.HasPrefix(line, "report_recipient:"):
+			out = append(out, "report_recipient: "+strconv.Quote(a.Recipient))
+		case strings.HasPrefix(line, "# locale:") && a.Locale != "en":
+			out = append(out, "locale: "+strconv.Quote(a.Locale))
+		default:
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// pickChannels resolves a comma-separated list of list numbers or channel names.
+func pickChannels(channels []slack.Channel, answer string) ([]Channel, error) {
+	var picked []Channel
+	for _, item := range strings.Split(answer, ",") {
+		item = strings.TrimPrefix(strings.TrimSpace(item), "#")
+		if item == "" {
+			continue
+		}
+		i := slices.IndexFunc(channels, func(ch slack.Channel) bool { return strings.EqualFold(ch.Name, item) })
+		if n, err := strconv.Atoi(item); err == nil {
+			i = n - 1
+		}
+		if i < 0 || i >= len(channels) {
+			return nil, fmt.Errorf("no channel %q in the list", item)
+		}
+		picked = append(picked, Channel{ID: channels[i].ID, Name: channels[i].Name})
+	}
+	if len(picked) == 0 {
+		return nil, errors.New("pick at least one channel")
+	}
+	return picked, nil
+}
+
+// exactUsers narrows matches to users whose ID or a name equals query.
+func exactUsers(matches []slack.User, query string) []slack.User {
+	var out []slack.User
+	for _, u := range matches {
+		if u.ID == query || strings.EqualFold(u.Profile.DisplayName, query) ||
+			strings.EqualFold(u.RealName, query) || strings.EqualFold(u.Name, query) {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// prompter asks questions on a terminal, or reads answers piped to stdin.
+type prompter struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+func (p *prompter) say(format string, args ...any) {
+	_, _ = fmt.Fprintf(p.out, format+"\n", args...)
+}
+
+// ask returns the trimmed answer to question, or def for an empty answer.
+func (p *prompter) ask(question, def string) string {
+	if def != "" && !strings.HasPrefix(def, "xox") {
+		_, _ = fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		_, _ = fmt.Fprintf(p.out, "%s: ", question)
+	}
+	if !p.in.Scan() {
+		_, _ = fmt.Fprintln(p.out)
+		fatalf("config init: no more input")
+	}
+	if answer := strings.TrimSpace(p.in.Text()); answer != "" {
+		return answer
+	}
+	return def
+}
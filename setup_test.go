@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestRenderConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers initAnswers
+	}{
+		{
+			name: "english",
+			answers: initAnswers{
+				Token:     "xoxb-test",
+				Channels:  []Channel{{ID: "C001", Name: "eng"}, {ID: "C002", Name: "prs"}},
+				Recipient: "U0LEAD1",
+				Locale:    "en",
+			},
+		},
+		{
+			name: "other locale",
+			answers: initAnswers{
+				Token:     "xoxb-test",
+				Channels:  []Channel{{ID: "C003", Name: "dev"}},
+				Recipient: "U0LEAD2",
+				Locale:    "de",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(renderConfig(exampleConfig, tt.answers)), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if cfg.SlackToken != tt.answers.Token {
+				t.Errorf("slack_token = %q, want %q", cfg.SlackToken, tt.answers.Token)
+			}
+			if !reflect.DeepEqual(cfg.Channels, tt.answers.Channels) {
+				t.Errorf("channels = %v, want %v", cfg.Channels, tt.answers.Channels)
+			}
+			if cfg.ReportRecipient != tt.answers.Recipient {
+				t.Errorf("report_recipient = %q, want %q", cfg.ReportRecipient, tt.answers.Recipient)
+			}
+			if want := strings.TrimPrefix(tt.answers.Locale, "en"); cfg.Locale != want {
+				t.Errorf("locale = %q, want %q", cfg.Locale, want)
+			}
+		})
+	}
+}
+
+func TestPickChannels(t *testing.T) {
+	channels := []slack.Channel{
+		{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C001"}, Name: "eng"}},
+		{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C002"}, Name: "prs"}},
+		{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C003"}, Name: "random"}},
+	}
+
+	tests := []struct {
+		name    string
+		answer  string
+		want    []Channel
+		wantErr bool
+	}{
+		{name: "numbers", answer: "1, 3", want: []Channel{{ID: "C001", Name: "eng"}, {ID: "C003", Name: "random"}}},
+		{name: "names", answer: "#prs,ENG", want: []Channel{{ID: "C002", Name: "prs"}, {ID: "C001", Name: "eng"}}},
+		{name: "mixed with blanks", answer: "2,, #random ", want: []Channel{{ID: "C002", Name: "prs"}, {ID: "C003", Name: "random"}}},
+		{name: "number out of range", answer: "4", wantErr: true},
+		{name: "zero", answer: "0", wantErr: true},
+		{name: "unknown name", answer: "#ops", wantErr: true},
+		{name: "empty", answer: "", wantErr: true},
+		{name: "only separators", answer: " , ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pickChannels(channels, tt.answer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pickChannels = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"strconv"
 	"time"
 
@@ -13,12 +15,12 @@ type SlackClient struct {
 }
 
 func NewSlackClient(token string) *SlackClient {
-	return &SlackClient{api: slack.New(token)}
+	return &SlackClient{api: slack.New(token, slack.OptionHTTPClient(apiHTTPClient("slack")))}
 }
 
 func (sc *SlackClient) FetchMessages(channelID string, oldest, latest time.Time) ([]slack.Message, error) {
 	var all []slack.Message
-	cursor := ""
+	cursor, page := "", 1
 	for {
 		resp, err := sc.api.GetConversationHistory(&slack.GetConversationHistoryParameters{
 			ChannelID: channelID,
@@ -34,10 +36,13 @@ func (sc *SlackClient) FetchMessages(channelID string, oldest, latest time.Time)
 			continue
 		}
 		all = append(all, resp.Messages...)
+		slog.Debug("slack: fetched messages page", "channel", channelID, "page", page, "messages", len(resp.Messages), "has_more", resp.HasMore)
 		if !resp.HasMore {
+			slog.Debug("slack: fetched messages", "channel", channelID, "oldest", oldest, "latest", latest, "messages", len(all))
 			return all, nil
 		}
 		cursor = resp.ResponseMetaData.NextCursor
+		page++
 	}
 }
 
@@ -55,6 +60,7 @@ func (sc *SlackClient) FetchMembers(channelID string) ([]string, error) {
 			continue
 		}
 		all = append(all, members...)
+		slog.Debug("slack: fetched members page", "channel", channelID, "members", len(members), "has_more", nextCursor != "")
 		if nextCursor == "" {
 			return all, nil
 		}
@@ -77,6 +83,7 @@ func (sc *SlackClient) FetchAllChannels() ([]slack.Channel, error) {
 			continue
 		}
 		all = append(all, channels...)
+		slog.Debug("slack: fetched channels page", "channels", len(channels), "has_more", nextCursor != "")
 		if nextCursor == "" {
 			return all, nil
 		}
@@ -84,25 +91,31 @@ func (sc *SlackClient) FetchAllChannels() ([]slack.Channel, error) {
 	}
 }
 
-// FetchUserNames returns a map of userID -> display name for all workspace users.
-func (sc *SlackClient) FetchUserNames() (map[string]string, error) {
+// FetchUsers returns every workspace user that is not deactivated.
+func (sc *SlackClient) FetchUsers() ([]slack.User, error) {
 	users, err := sc.api.GetUsers()
 	if err != nil {
 		return nil, fmt.Errorf("fetching users: %w", err)
 	}
-	names := make(map[string]string, len(users))
+	active := users[:0]
 	for _, u := range users {
-		if u.Deleted {
-			continue
-		}
-		name := u.Profile.DisplayName
-		if name == "" {
-			name = u.RealName
-		}
-		if name == "" {
-			name = u.Name
+		if !u.Deleted {
+			active = append(active, u)
 		}
-		names[u.ID] = name
+	}
+	slog.Debug("slack: fetched users", "users", len(users), "active", len(active))
+	return active, nil
+}
+
+// FetchUserNames returns a map of userID -> display name for all workspace users.
+func (sc *SlackClient) FetchUserNames() (map[string]string, error) {
+	users, err := sc.FetchUsers()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(users))
+	for i := range users {
+		names[users[i].ID] = displayName(&users[i])
 	}
 	return names, nil
 }
@@ -112,23 +125,126 @@ func (sc *SlackClient) GetUserDisplayName(userID string) (string, error) {
 	if err != nil {
 		return userID, err
 	}
-	if user.Profile.DisplayName != "" {
-		return user.Profile.DisplayName, nil
+	return displayName(user), nil
+}
+
+// displayName is the name members see: the display name, else the real
+// name, else the username.
+func displayName(u *slack.User) string {
+	if u.Profile.DisplayName != "" {
+		return u.Profile.DisplayName
+	}
+	if u.RealName != "" {
+		return u.RealName
 	}
-	if user.RealName != "" {
-		return user.RealName, nil
+	return u.Name
+}
+
+// AuthTest returns the bot user and team the token belongs to.
+func (sc *SlackClient) AuthTest() (*slack.AuthTestResponse, error) {
+	resp, err := sc.api.AuthTest()
+	if err != nil {
+		return nil, fmt.Errorf("checking token: %w", err)
+	}
+	return resp, nil
+}
+
+// ChannelInfo returns a channel, including whether the bot is a member.
+func (sc *SlackClient) ChannelInfo(channelID string) (*slack.Channel, error) {
+	ch, err := sc.api.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: channelID})
+	if err != nil {
+		return nil, fmt.Errorf("reading channel %s: %w", channelID, err)
+	}
+	return ch, nil
+}
+
+// GetProfileField returns the value of a custom profile field, or "" when
+// the user has not filled it in.
+func (sc *SlackClient) GetProfileField(userID, fieldID string) (string, error) {
+	profile, err := sc.api.GetUserProfile(&slack.GetUserProfileParameters{UserID: userID})
+	if err != nil {
+		return "", fmt.Errorf("reading profile of %s: %w", userID, err)
 	}
-	return user.Name, nil
+	return profile.Fields.ToMap()[fieldID].Value, nil
 }
 
-func (sc *SlackClient) SendDM(userID, text string) error {
-	_, _, err := sc.api.PostMessage(userID, slack.MsgOptionText(text, false))
+// Post sends a rendered message to a channel, DM, or thread (when threadTS is
+// set) and returns the channel and timestamp Slack assigned to it.
+func (sc *SlackClient) Post(channelID string, p SlackPost, threadTS string) (string, string, error) {
+	opts := []slack.MsgOption{slack.MsgOptionText(p.Text, false)}
+	if p.Blocks != nil {
+		opts = append(opts, slack.MsgOptionBlocks(p.Blocks...))
+	}
+	if threadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(threadTS))
+	}
+	channel, ts, err := sc.api.PostMessage(channelID, opts...)
 	if err != nil {
-		return fmt.Errorf("sending DM: %w", err)
+		return "", "", fmt.Errorf("posting to %s: %w", channelID, err)
+	}
+	slog.Debug("slack: posted message", "channel", channel, "ts", ts, "thread", threadTS, "blocks", len(p.Blocks))
+	return channel, ts, nil
+}
+
+// PostEphemeral shows text to userID only, in channelID or in its thread
+// threadTS when set.
+func (sc *SlackClient) PostEphemeral(channelID, userID, text, threadTS string) error {
+	opts := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if threadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(threadTS))
+	}
+	if _, err := sc.api.PostEphemeral(channelID, userID, opts...); err != nil {
+		return fmt.Errorf("posting to %s: %w", channelID, err)
+	}
+	slog.Debug("slack: posted ephemeral message", "channel", channelID, "user", userID, "thread", threadTS)
+	return nil
+}
+
+// ErrMessageGone reports that a message to update or delete no longer exists.
+var ErrMessageGone = errors.New("message no longer exists")
+
+// Update replaces the content of a previously posted message.
+func (sc *SlackClient) Update(channelID, ts string, p SlackPost) error {
+	opts := []slack.MsgOption{slack.MsgOptionText(p.Text, false)}
+	if p.Blocks != nil {
+		opts = append(opts, slack.MsgOptionBlocks(p.Blocks...))
+	}
+	if _, _, _, err := sc.api.UpdateMessage(channelID, ts, opts...); err != nil {
+		return fmt.Errorf("updating %s/%s: %w", channelID, ts, messageGone(err))
 	}
+	slog.Debug("slack: updated message", "channel", channelID, "ts", ts)
 	return nil
 }
 
+func (sc *SlackClient) Delete(channelID, ts string) error {
+	if _, _, err := sc.api.DeleteMessage(channelID, ts); err != nil {
+		return fmt.Errorf("deleting %s/%s: %w", channelID, ts, messageGone(err))
+	}
+	slog.Debug("slack: deleted message", "channel", channelID, "ts", ts)
+	return nil
+}
+
+// messageGone maps Slack's "no such message" errors to ErrMessageGone.
+func messageGone(err error) error {
+	var resp slack.SlackErrorResponse
+	if errors.As(err, &resp) {
+		switch resp.Err {
+		case "message_not_found", "channel_not_found", "cant_update_message", "is_archived":
+			return ErrMessageGone
+		}
+	}
+	return err
+}
+
+// OpenGroupDM opens (or reuses) a multi-person DM and returns its channel ID.
+func (sc *SlackClient) OpenGroupDM(userIDs []string) (string, error) {
+	ch, _, _, err := sc.api.OpenConversation(&slack.OpenConversationParameters{Users: userIDs})
+	if err != nil {
+		return "", fmt.Errorf("opening group DM: %w", err)
+	}
+	return ch.ID, nil
+}
+
 // retryOrFail returns nil if the error is a rate limit (caller should retry),
 // or returns the original error otherwise.
 func (sc *SlackClient) retryOrFail(err error) error {
@@ -136,6 +252,9 @@ func (sc *SlackClient) retryOrFail(err error) error {
 		return nil
 	}
 	if rle, ok := err.(*slack.RateLimitedError); ok {
+		slog.Warn("slack: rate limited, retrying", "retry_after", rle.RetryAfter)
+		metrics.add("zombie_detector_rate_limit_sleeps_total", 1, "api", "slack")
+		metrics.add("zombie_detector_rate_limit_sleep_seconds_total", rle.RetryAfter.Seconds(), "api", "slack")
 		time.Sleep(rle.RetryAfter)
 		return nil
 	}
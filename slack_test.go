@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+// fakeSlack is a Slack Web API server keeping channel history and posted
+// messages in memory. It records every call as "method channel args", with
+// " in ts" for replies in a thread.
+type fakeSlack struct {
+	mu      sync.Mutex
+	history map[string][]slack.Message // channel ID to its messages
+	posted  map[string]string          // "channel|ts" to the text of messages posted through chat.*
+	calls   []string
+	nextTS  int
+}
+
+// newFakeSlack starts a fake Slack server and returns it with a client
+// talking to it.
+func newFakeSlack(t *testing.T) (*fakeSlack, *SlackClient) {
+	t.Helper()
+	f := &fakeSlack{history: make(map[string][]slack.Message), posted: make(map[string]string)}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	return f, &SlackClient{api: slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/"))}
+}
+
+func (f *fakeSlack) serve(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	method, ch, ts := strings.TrimPrefix(r.URL.Path, "/"), r.Form.Get("channel"), r.Form.Get("ts")
+	resp := map[string]any{"ok": true}
+	switch method {
+	case "conversations.history":
+		oldest, latest := r.Form.Get("oldest"), r.Form.Get("latest")
+		f.calls = append(f.calls, fmt.Sprintf("%s %s %s-%s", method, ch, oldest, latest))
+		lo, _ := strconv.ParseFloat(oldest, 64)
+		hi, _ := strconv.ParseFloat(latest, 64)
+		var msgs []slack.Message
+		for _, m := range f.history[ch] {
+			// both bounds are exclusive, as with Slack's default inclusive=false
+			if t, _ := strconv.ParseFloat(m.Timestamp, 64); t > lo && t < hi {
+				msgs = append(msgs, m)
+			}
+		}
+		sort.Slice(msgs, func(i, j int) bool { return msgs[i].Timestamp > msgs[j].Timestamp })
+		resp["messages"], resp["has_more"] = msgs, false
+	case "chat.postMessage":
+		f.nextTS++
+		ts = fmt.Sprintf("1700000000.%06d", f.nextTS)
+		call := fmt.Sprintf("%s %s %s", method, ch, ts)
+		if thread := r.Form.Get("thread_ts"); thread != "" {
+			call += " in " + thread
+		}
+		f.calls = append(f.calls, call)
+		f.posted[ch+"|"+ts] = r.Form.Get("text")
+		resp["channel"], resp["ts"] = ch, ts
+	case "chat.update", "chat.delete":
+		f.calls = append(f.calls, fmt.Sprintf("%s %s %s", method, ch, ts))
+		if _, ok := f.posted[ch+"|"+ts]; !ok {
+			resp = map[string]any{"ok": false, "error": "message_not_found"}
+			break
+		}
+		if method == "chat.update" {
+			f.posted[ch+"|"+ts] = r.Form.Get("text")
+		} else {
+			delete(f.posted, ch+"|"+ts)
+		}
+		resp["channel"], resp["ts"] = ch, ts
+	default:
+		resp = map[string]any{"ok": false, "error": "unknown_method"}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// takeCalls returns the calls made so far and forgets them.
+func (f *fakeSlack) takeCalls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := f.calls
+	f.calls = nil
+	return calls
+}
+
+// setHistory replaces a channel's history.
+func (f *fakeSlack) setHistory(channelID string, msgs ...slack.Message) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.history[channelID] = msgs
+}
+
+// postedTexts returns the texts of the messages currently posted in a
+// channel, in posting order.
+func (f *fakeSlack) postedTexts(channelID string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for k := range f.posted {
+		if strings.HasPrefix(k, channelID+"|") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	texts := make([]string, len(keys))
+	for i, k := range keys {
+		texts[i] = f.posted[k]
+	}
+	return texts
+}
+
+// removePosted deletes a posted message behind the client's back, as a
+// channel member might.
+func (f *fakeSlack) removePosted(channelID, ts string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.posted, channelID+"|"+ts)
+}
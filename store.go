@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var bucketRuns = []byte("runs")
+
+// Store persists run history and other state between runs in a local bbolt file.
+type Store struct {
+	db *bolt.DB
+}
+
+func OpenStore(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 10 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening state %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{bucketRuns, bucketMessages, bucketChannels, bucketThreads, bucketPosts, bucketSchedules, bucketExcusals, bucketNudges, bucketEscalations} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("initializing state: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) put(bucket []byte, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(key), data)
+	})
+}
+
+// get decodes the value at key into v and reports whether it existed.
+func (s *Store) get(bucket []byte, key string, v any) (bool, error) {
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if raw := tx.Bucket(bucket).Get([]byte(key)); raw != nil {
+			data = append([]byte(nil), raw...)
+		}
+		return nil
+	})
+	if err != nil || data == nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (s *Store) delete(bucket []byte, key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Delete([]byte(key))
+	})
+}
+
+// scan calls fn for every key in [start, end) in key order; an empty end scans to the last key.
+func (s *Store) scan(bucket []byte, start, end string, fn func(k, v []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+		for k, v := c.Seek([]byte(start)); k != nil; k, v = c.Next() {
+			if end != "" && bytes.Compare(k, []byte(end)) >= 0 {
+				break
+			}
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MemberRecord is one member's outcome within a recorded run.
+type MemberRecord struct {
+	UserID      string         `json:"user_id"`
+	DisplayName string         `json:"display_name"`
+	Class       Classification `json:"class"`
+	Royal       bool           `json:"royal,omitempty"`
+	Messages    int            `json:"messages"`
+	PRs         int            `json:"prs"`
+	Required    int            `json:"required"`
+	Links       []string       `json:"links,omitempty"`
+}
+
+// RunRecord is the persisted form of a Report.
+type RunRecord struct {
+	Mode       string         `json:"mode"`
+	Source     string         `json:"source"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	RecordedAt time.Time      `json:"recorded_at"`
+	Members    []MemberRecord `json:"members"`
+}
+
+// runKey orders runs by period start. Runs of the same mode starting at the
+// same time describe the same period, so a later run replaces an earlier one.
+func runKey(from time.Time, mode string) string {
+	return from.UTC().Format(time.RFC3339) + "|" + mode
+}
+
+func NewRunRecord(r *Report) RunRecord {
+	rec := RunRecord{Mode: r.Mode, Source: r.Source, From: r.From, To: r.To, RecordedAt: time.Now()}
+	addZombies := func(members []MemberReport, royal bool) {
+		for _, m := range members {
+			rec.Members = append(rec.Members, MemberRecord{
+				UserID: m.UserID, DisplayName: m.DisplayName, Class: ClassZombie, Royal: royal,
+			})
+		}
+	}
+	addActive := func(members []ActiveMember, class Classification) {
+		for _, a := range members {
+			mr := MemberRecord{
+				UserID: a.UserID, DisplayName: a.DisplayName, Class: class,
+				Messages: len(a.Messages), PRs: a.Count, Required: a.Required,
+			}
+			for _, m := range a.Messages {
+				mr.Links = append(mr.Links, m.PRURL)
+			}
+			for _, pr := range a.GitHubPRs {
+				mr.Links = append(mr.Links, pr.URL)
+			}
+			rec.Members = append(rec.Members, mr)
+		}
+	}
+	addZombies(r.RoyalZombies, true)
+	addZombies(r.OtherZombies, false)
+	addActive(r.BelowExpectation, ClassBelow)
+	addActive(r.Active, ClassActive)
+	for _, m := range r.OffSchedule {
+		rec.Members = append(rec.Members, MemberRecord{UserID: m.UserID, DisplayName: m.DisplayName, Class: ClassOffSchedule})
+	}
+	for _, m := range r.Excused {
+		rec.Members = append(rec.Members, MemberRecord{UserID: m.UserID, DisplayName: m.DisplayName, Class: ClassExcused, PRs: m.Count})
+	}
+	return rec
+}
+
+func (s *Store) SaveRun(rec RunRecord) error {
+	if err := s.put(bucketRuns, runKey(rec.From, rec.Mode), rec); err != nil {
+		return fmt.Errorf("saving run: %w", err)
+	}
+	return nil
+}
+
+// Runs returns recorded runs whose period starts in [from, to), oldest first.
+// Zero bounds are open-ended.
+func (s *Store) Runs(from, to time.Time) ([]RunRecord, error) {
+	var start, end string
+	if !from.IsZero() {
+		start = from.UTC().Format(time.RFC3339)
+	}
+	if !to.IsZero() {
+		end = to.UTC().Format(time.RFC3339)
+	}
+	var runs []RunRecord
+	err := s.scan(bucketRuns, start, end, func(_, v []byte) error {
+		var rec RunRecord
+		if err := json.Unmarshal(v, &rec); err != nil {
+			return err
+		}
+		runs = append(runs, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading runs: %w", err)
+	}
+	return runs, nil
+}
+
+// RecentRuns returns the runs of mode whose period starts before before,
+// oldest first. It reads backwards from before and stops after the first run
+// for which enough reports true.
+func (s *Store) RecentRuns(mode string, before time.Time, enough func(RunRecord) bool) ([]RunRecord, error) {
+	var runs []RunRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketRuns).Cursor()
+		k, v := c.Seek([]byte(before.UTC().Format(time.RFC3339)))
+		if k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+		for ; k != nil; k, v = c.Prev() {
+			if !bytes.HasSuffix(k, []byte("|"+mode)) {
+				continue
+			}
+			var rec RunRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			runs = append(runs, rec)
+			if enough(rec) {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading runs: %w", err)
+	}
+	slices.Reverse(runs)
+	return runs, nil
+}
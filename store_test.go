@@ -0,0 +1,86 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStoreRuns(t *testing.T) {
+	store := openTestStore(t)
+	day := func(n int) time.Time { return time.Date(2024, 5, n, 0, 0, 0, 0, time.UTC) }
+	run := func(mode string, from int, class Classification) RunRecord {
+		return RunRecord{
+			Mode: mode, Source: "both", From: day(from), To: day(from + 1), RecordedAt: day(from + 1),
+			Members: []MemberRecord{{UserID: "U1", DisplayName: "alice", Class: class, PRs: 1, Required: 1, Links: []string{"https://github.com/org/repo/pull/1"}}},
+		}
+	}
+	for _, rec := range []RunRecord{
+		run("daily", 3, ClassActive),
+		run("daily", 1, ClassZombie),
+		run("weekly", 1, ClassActive),
+		run("daily", 2, ClassZombie),
+		run("daily", 2, ClassActive), // the same period again replaces the first
+	} {
+		if err := store.SaveRun(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []RunRecord
+	}{
+		{name: "all, oldest first", want: []RunRecord{run("daily", 1, ClassZombie), run("weekly", 1, ClassActive), run("daily", 2, ClassActive), run("daily", 3, ClassActive)}},
+		{name: "from is inclusive", from: day(2), want: []RunRecord{run("daily", 2, ClassActive), run("daily", 3, ClassActive)}},
+		{name: "to is exclusive", to: day(2), want: []RunRecord{run("daily", 1, ClassZombie), run("weekly", 1, ClassActive)}},
+		{name: "empty range", from: day(4), to: day(9)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Runs(tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d runs, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i], tt.want[i]) || !got[i].From.Equal(tt.want[i].From) {
+					t.Errorf("run %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewRunRecord(t *testing.T) {
+	r := &Report{
+		Mode: "daily", Source: "slack",
+		From: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+		RoyalZombies: []MemberReport{{UserID: "U1", DisplayName: "king"}},
+		OtherZombies: []MemberReport{{UserID: "U2", DisplayName: "bob"}},
+		BelowExpectation: []ActiveMember{{UserID: "U3", DisplayName: "carol", Count: 1, Required: 2,
+			Messages: []MessageLink{{PRURL: "https://github.com/org/repo/pull/1"}}}},
+		Active: []ActiveMember{{UserID: "U4", DisplayName: "dave", Count: 1, Required: 1,
+			GitHubPRs: []PRLink{{URL: "https://github.com/org/repo/pull/2"}}}},
+		OffSchedule: []MemberReport{{UserID: "U5", DisplayName: "erin"}},
+		Excused:     []ExcusedMember{{UserID: "U6", DisplayName: "frank", Count: 1}},
+	}
+	want := []MemberRecord{
+		{UserID: "U1", DisplayName: "king", Class: ClassZombie, Royal: true},
+		{UserID: "U2", DisplayName: "bob", Class: ClassZombie},
+		{UserID: "U3", DisplayName: "carol", Class: ClassBelow, Messages: 1, PRs: 1, Required: 2, Links: []string{"https://github.com/org/repo/pull/1"}},
+		{UserID: "U4", DisplayName: "dave", Class: ClassActive, PRs: 1, Required: 1, Links: []string{"https://github.com/org/repo/pull/2"}},
+		{UserID: "U5", DisplayName: "erin", Class: ClassOffSchedule},
+		{UserID: "U6", DisplayName: "frank", Class: ClassExcused, PRs: 1},
+	}
+	rec := NewRunRecord(r)
+	if rec.Mode != "daily" || rec.Source != "slack" || !rec.From.Equal(r.From) || !rec.To.Equal(r.To) {
+		t.Errorf("record header = %+v", rec)
+	}
+	if !reflect.DeepEqual(rec.Members, want) {
+		t.Errorf("members = %+v, want %+v", rec.Members, want)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"time"
+)
+
+const trendWindow = 28 * 24 * time.Hour
+
+// Trend compares a member's activity with their trailing average.
+type Trend string
+
+const (
+	TrendUp   Trend = "up"
+	TrendFlat Trend = "flat"
+	TrendDown Trend = "down"
+)
+
+func (t Trend) Arrow() string {
+	switch t {
+	case TrendUp:
+		return "↑"
+	case TrendDown:
+		return "↓"
+	case TrendFlat:
+		return "→"
+	}
+	return ""
+}
+
+// AnnotateHistory fills in zombie streaks and activity trends on r from earlier
+// runs of the same mode. Members with a streak of at least chronicAfter runs
+// are reported as chronic.
+func AnnotateHistory(r *Report, runs []RunRecord, chronicAfter int) {
+	var prior []RunRecord
+	for _, run := range runs {
+		if run.Mode == r.Mode && run.From.Before(r.From) {
+			prior = append(prior, run)
+		}
+	}
+	r.History = true
+	r.ChronicAfter = chronicAfter
+
+	for _, zombies := range [][]MemberReport{r.RoyalZombies, r.OtherZombies} {
+		for i := range zombies {
+			zombies[i].Streak, zombies[i].StreakFrom = zombieStreak(zombies[i].UserID, prior, r.From)
+		}
+	}
+	for _, members := range [][]ActiveMember{r.BelowExpectation, r.Active} {
+		for i := range members {
+			members[i].Trend = activityTrend(members[i].UserID, members[i].Count, prior, r.From)
+		}
+	}
+}
+
+// historyEnough returns the stop condition for reading r's history
+// backwards with Store.RecentRuns: the runs read are enough once they cover
+// the trend window and have broken every zombie's streak with a run in which
+// the member was active or below target.
+func historyEnough(r *Report) func(RunRecord) bool {
+	open := make(map[string]bool)
+	for _, zombies := range [][]MemberReport{r.RoyalZombies, r.OtherZombies} {
+		for _, z := range zombies {
+			open[z.UserID] = true
+		}
+	}
+	windowStart := r.From.Add(-trendWindow)
+	return func(run RunRecord) bool {
+		for id := range open {
+			if m, ok := findMember(run, id); ok && (m.Class == ClassActive || m.Class == ClassBelow) {
+				delete(open, id)
+			}
+		}
+		return len(open) == 0 && run.From.Before(windowStart)
+	}
+}
+
+// zombieStreak counts consecutive zombie runs ending with the current one,
+// which starts at from, and returns the period start of the first of them.
+func zombieStreak(userID string, prior []RunRecord, from time.Time) (int, time.Time) {
+	streak := 1
+	for i := len(prior) - 1; i >= 0; i-- {
+		m, ok := findMember(prior[i], userID)
+		if !ok || m.Class == ClassOffSchedule || m.Class == ClassExcused {
+			continue
+		}
+		if m.Class != ClassZombie {
+			break
+		}
+		streak++
+		from = prior[i].From
+	}
+	return streak, from
+}
+
+// activityTrend compares count with the member's average over the trailing
+// four weeks, treating a ±20% band as flat. It is empty without prior data.
+func activityTrend(userID string, count int, prior []RunRecord, from time.Time) Trend {
+	total, n := 0, 0
+	for _, run := range prior {
+		if run.From.Before(from.Add(-trendWindow)) {
+			continue
+		}
+		if m, ok := findMember(run, userID); ok && m.Class != ClassOffSchedule && m.Class != ClassExcused {
+			total += m.PRs
+			n++
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	avg := float64(total) / float64(n)
+	switch c := float64(count); {
+	case c > avg*1.2:
+		return TrendUp
+	case c < avg*0.8:
+		return TrendDown
+	default:
+		return TrendFlat
+	}
+}
+
+func findMember(run RunRecord, userID string) (MemberRecord, bool) {
+	for _, m := range run.Members {
+		if m.UserID == userID {
+			return m, true
+		}
+	}
+	return MemberRecord{}, false
+}
+
+// streakLabel formats a streak in the unit of the report mode, e.g. "3d".
+func streakLabel(mode string, streak int) string {
+	return localeEN.Streak(mode, streak)
+}
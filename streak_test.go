@@ -0,0 +1,150 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// historyRun records U1 with class, and U2 as active, for the daily period starting on day n.
+func historyRun(mode string, n int, class Classification, prs int) RunRecord {
+	from := time.Date(2024, 5, n, 0, 0, 0, 0, time.UTC)
+	return RunRecord{Mode: mode, From: from, To: from.AddDate(0, 0, 1), Members: []MemberRecord{
+		{UserID: "U1", Class: class, PRs: prs},
+		{UserID: "U2", Class: ClassActive, PRs: 1},
+	}}
+}
+
+func TestAnnotateHistoryStreak(t *testing.T) {
+	tests := []struct {
+		name     string
+		runs     []RunRecord
+		want     int
+		wantFrom int // day the streak starts
+	}{
+		{name: "no history", want: 1, wantFrom: 20},
+		{name: "after an active run", runs: []RunRecord{historyRun("daily", 18, ClassZombie, 0), historyRun("daily", 19, ClassActive, 1)}, want: 1, wantFrom: 20},
+		{name: "consecutive zombie runs", runs: []RunRecord{historyRun("daily", 17, ClassActive, 1), historyRun("daily", 18, ClassZombie, 0), historyRun("daily", 19, ClassZombie, 0)}, want: 3, wantFrom: 18},
+		{name: "below target breaks the streak", runs: []RunRecord{historyRun("daily", 18, ClassZombie, 0), historyRun("daily", 19, ClassBelow, 1)}, want: 1, wantFrom: 20},
+		{
+			name: "gaps in the calendar do not break the streak",
+			runs: []RunRecord{historyRun("daily", 10, ClassZombie, 0), historyRun("daily", 15, ClassZombie, 0)},
+			want: 3, wantFrom: 10,
+		},
+		{
+			name: "off-schedule, excused and absent runs are skipped",
+			runs: []RunRecord{
+				historyRun("daily", 14, ClassActive, 1), historyRun("daily", 15, ClassZombie, 0),
+				historyRun("daily", 16, ClassOffSchedule, 0), historyRun("daily", 17, ClassExcused, 0),
+				{Mode: "daily", From: time.Date(2024, 5, 18, 0, 0, 0, 0, time.UTC)},
+				historyRun("daily", 19, ClassZombie, 0),
+			},
+			want: 3, wantFrom: 15,
+		},
+		{
+			name: "runs of other modes are ignored",
+			runs: []RunRecord{historyRun("daily", 18, ClassZombie, 0), historyRun("weekly", 13, ClassActive, 3), historyRun("weekly", 19, ClassActive, 1)},
+			want: 2, wantFrom: 18,
+		},
+		{name: "later runs are ignored", runs: []RunRecord{historyRun("daily", 19, ClassZombie, 0), historyRun("daily", 21, ClassActive, 1)}, want: 2, wantFrom: 19},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+			r := &Report{Mode: "daily", From: from, To: from.AddDate(0, 0, 1), OtherZombies: []MemberReport{{UserID: "U1"}}}
+			AnnotateHistory(r, tt.runs, 3)
+			z := r.OtherZombies[0]
+			wantFrom := time.Date(2024, 5, tt.wantFrom, 0, 0, 0, 0, time.UTC)
+			if z.Streak != tt.want || !z.StreakFrom.Equal(wantFrom) {
+				t.Errorf("streak = %d from %s, want %d from %s", z.Streak, z.StreakFrom.Format(dateLayout), tt.want, wantFrom.Format(dateLayout))
+			}
+		})
+	}
+}
+
+func TestAnnotateHistoryTrend(t *testing.T) {
+	tests := []struct {
+		name  string
+		runs  []RunRecord
+		count int
+		want  Trend
+	}{
+		{name: "no history", count: 2, want: ""},
+		{name: "up", runs: []RunRecord{historyRun("daily", 18, ClassActive, 2), historyRun("daily", 19, ClassActive, 2)}, count: 3, want: TrendUp},
+		{name: "flat within 20%", runs: []RunRecord{historyRun("daily", 18, ClassActive, 5), historyRun("daily", 19, ClassActive, 5)}, count: 4, want: TrendFlat},
+		{name: "down", runs: []RunRecord{historyRun("daily", 18, ClassActive, 4), historyRun("daily", 19, ClassBelow, 2)}, count: 1, want: TrendDown},
+		{name: "zombie runs count as none", runs: []RunRecord{historyRun("daily", 18, ClassZombie, 0), historyRun("daily", 19, ClassActive, 2)}, count: 1, want: TrendFlat},
+		{name: "off-schedule runs are left out", runs: []RunRecord{historyRun("daily", 18, ClassOffSchedule, 0), historyRun("daily", 19, ClassActive, 2)}, count: 1, want: TrendDown},
+		{name: "runs before the window are left out", runs: []RunRecord{historyRun("daily", 1, ClassActive, 9), historyRun("daily", 19, ClassActive, 1)}, count: 1, want: TrendFlat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := time.Date(2024, 5, 30, 0, 0, 0, 0, time.UTC)
+			r := &Report{Mode: "daily", From: from, To: from.AddDate(0, 0, 1), Active: []ActiveMember{{UserID: "U1", Count: tt.count}}}
+			AnnotateHistory(r, tt.runs, 3)
+			if got := r.Active[0].Trend; got != tt.want {
+				t.Errorf("trend = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecentRuns(t *testing.T) {
+	store := openTestStore(t)
+	runs := []RunRecord{
+		historyRun("daily", 1, ClassActive, 1),
+		historyRun("daily", 2, ClassZombie, 0),
+		historyRun("weekly", 3, ClassActive, 1),
+		historyRun("daily", 3, ClassActive, 1),
+		historyRun("daily", 10, ClassZombie, 0),
+		historyRun("daily", 11, ClassZombie, 0),
+		historyRun("daily", 12, ClassZombie, 0),
+	}
+	for _, run := range runs {
+		if err := store.SaveRun(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	from := time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC)
+	days := func(runs []RunRecord) []int {
+		var out []int
+		for _, run := range runs {
+			out = append(out, run.From.Day())
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		report *Report
+		want   []int
+	}{
+		{
+			// within the trend window, so everything before from is read
+			name:   "trend window",
+			report: &Report{Mode: "daily", From: from},
+			want:   []int{1, 2, 3, 10, 11},
+		},
+		{
+			name:   "stops once streaks are broken and the window is covered",
+			report: &Report{Mode: "daily", From: from.AddDate(0, 0, 28), OtherZombies: []MemberReport{{UserID: "U1"}}},
+			want:   []int{3, 10, 11, 12},
+		},
+		{
+			name:   "only the report's mode",
+			report: &Report{Mode: "weekly", From: from},
+			want:   []int{3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.RecentRuns(tt.report.Mode, tt.report.From, historyEnough(tt.report))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g := days(got); !slices.Equal(g, tt.want) {
+				t.Errorf("runs on days %v, want %v", g, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Teams rejects webhook payloads above about 28 KB; keep each one well below.
+const teamsMaxPayload = 24000
+
+// TeamsSink posts Adaptive Cards to a Microsoft Teams incoming webhook.
+type TeamsSink struct {
+	dest Destination
+}
+
+func (s *TeamsSink) Name() string {
+	return fmt.Sprintf("teams webhook %s (%s)", s.dest.URL, s.dest.Detail)
+}
+
+// payloads renders the report as webhook messages of one Adaptive Card each,
+// with the report's units as text blocks, starting a new card whenever the
+// encoded message would exceed teamsMaxPayload. The first non-blank unit is
+// the card title; a template rendering nothing gets the standard title.
+func (s *TeamsSink) payloads(r *Report) ([][]byte, error) {
+	units := markdownUnits(reportView(r, s.dest.Detail), true)
+	for len(units) > 0 && strings.TrimSpace(units[0]) == "" {
+		units = units[1:]
+	}
+	title := reportTitle(r)
+	if len(units) > 0 {
+		title, units = strings.TrimSpace(units[0]), units[1:]
+	}
+
+	var out [][]byte
+	var body []map[string]any
+	current, err := teamsPayload(title, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, unit := range units {
+		// JSON escaping can grow text, so split units well below the limit
+		for _, part := range splitSafe(strings.TrimSpace(unit), teamsMaxPayload/2) {
+			// Adaptive Card markdown needs blank lines for line breaks
+			text := strings.ReplaceAll(strings.TrimSpace(part), "\n", "\n\n")
+			if text == "" {
+				continue
+			}
+			block := map[string]any{"type": "TextBlock", "text": text, "wrap": true}
+			next, err := teamsPayload(title, len(out), append(body, block))
+			if err != nil {
+				return nil, err
+			}
+			if len(next) > teamsMaxPayload && len(body) > 0 {
+				out = append(out, current)
+				body = nil
+				if next, err = teamsPayload(title, len(out), []map[string]any{block}); err != nil {
+					return nil, err
+				}
+			}
+			if len(next) > teamsMaxPayload {
+				return nil, fmt.Errorf("teams: report section of %d bytes does not fit in a card", len(next))
+			}
+			body = append(body, block)
+			current = next
+		}
+	}
+	return append(out, current), nil
+}
+
+// teamsPayload encodes one webhook message holding a card with the report
+// title, numbered from the second part on, followed by body.
+func teamsPayload(title string, part int, body []map[string]any) ([]byte, error) {
+	header := map[string]any{"type": "TextBlock", "text": title, "size": "Large", "weight": "Bolder", "wrap": true}
+	if part > 0 {
+		header["text"] = fmt.Sprintf("%s (part %d)", title, part+1)
+	}
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"msteams": map[string]string{"width": "Full"},
+		"body":    append([]map[string]any{header}, body...),
+	}
+	return json.Marshal(map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	})
+}
+
+func (s *TeamsSink) Deliver(r *Report) (int, error) {
+	payloads, err := s.payloads(r)
+	if err != nil {
+		return 0, err
+	}
+	for i, body := range payloads {
+		if err := postWithRetry(s.dest.URL, map[string]string{"Content-Type": "application/json"}, body, s.dest.retries()); err != nil {
+			return i, err
+		}
+	}
+	return len(payloads), nil
+}
+
+func (s *TeamsSink) Preview(w io.Writer, r *Report) error {
+	payloads, err := s.payloads(r)
+	if err != nil {
+		return err
+	}
+	for _, body := range payloads {
+		if _, err := fmt.Fprintf(w, "%s\n", body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/report.txt
+var defaultTextTemplate string
+
+// unitBreak separates the units of a rendered text report. Units are kept
+// within one Slack message where possible.
+const unitBreak = "\f"
+
+var textTemplateFuncs = template.FuncMap{
+	"break":       func() string { return unitBreak },
+	"slackLink":   func(url, label string) string { return "<" + url + "|" + label + ">" },
+	"dedup":       dedupPRs,
+	"byDay":       groupByDay,
+	"join":        strings.Join,
+	"inc":         func(i int) int { return i + 1 },
+	"streakLabel": streakLabel,
+}
+
+var defaultReportTemplate = template.Must(template.New("report.txt").Funcs(textTemplateFuncs).Parse(defaultTextTemplate))
+
+// LoadReportTemplate parses a text report template file, trying it against an
+// empty report so that mistakes surface at startup rather than at send time.
+func LoadReportTemplate(path string) (*template.Template, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading report template: %w", err)
+	}
+	tmpl, err := template.New(path).Funcs(textTemplateFuncs).Parse(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("parsing report template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, newReportData(&Report{})); err != nil {
+		return nil, fmt.Errorf("report template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// reportData is what text report templates execute against: every Report
+// field, the rendered title and footer, and the locale's messages via .T.
+type reportData struct {
+	*Report
+	*Locale
+	Title  string
+	Footer string
+}
+
+func newReportData(r *Report) reportData {
+	return reportData{Report: r, Locale: r.loc(), Title: reportTitle(r), Footer: reportFooter(r)}
+}
+
+// ZombieGroup is a titled list of zombie labels, split into newly and
+// chronic zombies when history is known.
+type ZombieGroup struct {
+	*Locale
+	Header  string
+	Members []MemberReport
+	History bool
+	Labels  []string // "@name", with the streak when history is known
+	Newly   []string
+	Chronic []string
+}
+
+// Group builds the ZombieGroup for members under header.
+func (d reportData) Group(header string, members []MemberReport) ZombieGroup {
+	g := ZombieGroup{Locale: d.Locale, Header: header, Members: members, History: d.History}
+	for _, z := range members {
+		label := zombieLabel(z, d.Report)
+		g.Labels = append(g.Labels, label)
+		if d.isChronic(z) {
+			g.Chronic = append(g.Chronic, label)
+		} else {
+			g.Newly = append(g.Newly, label)
+		}
+	}
+	return g
+}
+
+// MemberLine is an active member with their name and links rendered.
+type MemberLine struct {
+	ActiveMember
+	Name  string // "@name", with the PR count when below target and the trend arrow
+	Links string // Slack message links, grouped by day with --by-day, then GitHub PRs
+}
+
+// Member renders the line for one active member.
+func (d reportData) Member(a ActiveMember) MemberLine {
+	return MemberLine{ActiveMember: a, Name: memberName(a), Links: memberLinks(a, d.Report)}
+}
+
+// ExcusalLabel describes why and until when a member is excused.
+func (d reportData) ExcusalLabel(e ExcusedMember) string {
+	return excusalLabel(e, d.Locale)
+}
+
+// Chronic reports whether z has been a zombie for at least chronic_streak runs.
+func (d reportData) Chronic(z MemberReport) bool {
+	return d.isChronic(z)
+}
+
+// reportUnits renders the report in Slack mrkdwn as logical units, each of
+// which should stay within one message where possible. A custom template
+// that fails falls back to the default one.
+func reportUnits(r *Report) []string {
+	tmpl := r.template
+	if tmpl == nil {
+		tmpl = defaultReportTemplate
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, newReportData(r)); err != nil {
+		slog.Warn("report template failed, using the default", "err", err)
+		b.Reset()
+		if err := defaultReportTemplate.Execute(&b, newReportData(r)); err != nil {
+			panic(err)
+		}
+	}
+	return splitUnits(b.String())
+}
+
+// splitUnits splits template output at unit breaks, or at line ends when the
+// template has none.
+func splitUnits(text string) []string {
+	var units []string
+	if strings.Contains(text, unitBreak) {
+		units = strings.Split(text, unitBreak)
+	} else {
+		units = strings.SplitAfter(text, "\n")
+	}
+	out := units[:0]
+	for _, u := range units {
+		if u != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
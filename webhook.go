@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const signatureHeader = "X-Zombie-Signature-256"
+
+// WebhookSink posts the report to an HTTP endpoint, as the JSON report by
+// default or rendered through a per-sink template.
+type WebhookSink struct {
+	dest Destination
+	tmpl *template.Template // nil sends the JSON report
+}
+
+func NewWebhookSink(d Destination) (*WebhookSink, error) {
+	s := &WebhookSink{dest: d}
+	if d.Template != "" {
+		src, err := os.ReadFile(d.Template)
+		if err != nil {
+			return nil, fmt.Errorf("reading webhook template: %w", err)
+		}
+		s.tmpl, err = template.New(d.Template).Funcs(template.FuncMap{
+			"json": func(v any) (string, error) {
+				b, err := json.Marshal(v)
+				return string(b), err
+			},
+			"join": strings.Join,
+		}).Parse(string(src))
+		if err != nil {
+			return nil, fmt.Errorf("parsing webhook template: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook " + s.dest.URL
+}
+
+func (s *WebhookSink) Deliver(r *Report) (int, error) {
+	body, err := s.body(r)
+	if err != nil {
+		return 0, err
+	}
+	headers := map[string]string{"Content-Type": s.dest.ContentType}
+	for k, v := range s.dest.Headers {
+		headers[http.CanonicalHeaderKey(k)] = v
+	}
+	if s.dest.Secret != "" {
+		headers[signatureHeader] = "sha256=" + sign(s.dest.Secret, body)
+	}
+	if err := postWithRetry(s.dest.URL, headers, body, s.dest.retries()); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (s *WebhookSink) Preview(w io.Writer, r *Report) error {
+	body, err := s.body(r)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "POST %s\n%s\n", s.dest.URL, body)
+	return err
+}
+
+func (s *WebhookSink) body(r *Report) ([]byte, error) {
+	rj := NewReportJSON(reportView(r, s.dest.Detail))
+	if s.tmpl == nil {
+		return json.Marshal(rj)
+	}
+	var buf bytes.Buffer
+	if err := s.tmpl.Execute(&buf, rj); err != nil {
+		return nil, fmt.Errorf("rendering webhook template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// sign returns the hex HMAC-SHA256 of body, which receivers recompute with
+// the shared secret to verify the payload.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+var webhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// postWithRetry POSTs body, retrying network errors, 429 and 5xx responses
+// with exponential backoff starting at one second.
+func postWithRetry(url string, headers map[string]string, body []byte, retries int) error {
+	backoff := time.Second
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			slog.Warn("webhook: retrying", "attempt", attempt, "backoff", backoff, "err", lastErr)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := webhookClient.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("posting to %s: %w", url, err)
+			continue
+		}
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		_ = resp.Body.Close()
+		if resp.StatusCode < 300 {
+			slog.Debug("webhook: posted", "status", resp.StatusCode, "bytes", len(body))
+			return nil
+		}
+		lastErr = fmt.Errorf("posting to %s: %s: %s", url, resp.Status, strings.TrimSpace(string(respBody)))
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return lastErr
+		}
+	}
+	return lastErr
+}
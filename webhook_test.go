@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		secret, body, want string
+	}{
+		{"secret", `{"mode":"daily"}`, "e1342347d844996ddbb78a77d323f01c74f66c95f9af2f1127632acf14077dd9"},
+		{"", "", "b613679a0814d9ec772f95d778c35fc5ff1697c493715653c6c712144292c5ad"},
+		{"key", "The quick brown fox jumps over the lazy dog", "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+	}
+	for _, tt := range tests {
+		if got := sign(tt.secret, []byte(tt.body)); got != tt.want {
+			t.Errorf("sign(%q, %q) = %s, want %s", tt.secret, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookSinkDeliver(t *testing.T) {
+	tmpl := filepath.Join(t.TempDir(), "body.tmpl")
+	if err := os.WriteFile(tmpl, []byte("{{.Mode}} report"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	zero := 0
+
+	tests := []struct {
+		name        string
+		dest        Destination
+		status      int
+		wantType    string
+		wantBody    string
+		wantErr     bool
+		wantAttempt int
+	}{
+		{
+			name:     "json report",
+			dest:     Destination{Type: "webhook", Secret: "s3"},
+			status:   http.StatusOK,
+			wantType: "application/json",
+		},
+		{
+			name:     "template with content type",
+			dest:     Destination{Type: "webhook", Template: tmpl, ContentType: "text/plain"},
+			status:   http.StatusOK,
+			wantType: "text/plain",
+			wantBody: "daily report",
+		},
+		{
+			name:     "header overrides content type",
+			dest:     Destination{Type: "webhook", Headers: map[string]string{"content-type": "application/xml"}},
+			status:   http.StatusOK,
+			wantType: "application/xml",
+		},
+		{
+			name:        "retries disabled",
+			dest:        Destination{Type: "webhook", Retries: &zero},
+			status:      http.StatusServiceUnavailable,
+			wantType:    "application/json",
+			wantErr:     true,
+			wantAttempt: 1,
+		},
+		{
+			name:        "client errors are not retried",
+			dest:        Destination{Type: "webhook"},
+			status:      http.StatusBadRequest,
+			wantType:    "application/json",
+			wantErr:     true,
+			wantAttempt: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			var gotType, gotSig string
+			var gotBody []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				gotType = r.Header.Get("Content-Type")
+				gotSig = r.Header.Get(signatureHeader)
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			tt.dest.URL = srv.URL
+			if err := tt.dest.validate(); err != nil {
+				t.Fatal(err)
+			}
+			sink, err := NewWebhookSink(tt.dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = sink.Deliver(&Report{Mode: "daily"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Deliver error = %v", err)
+			}
+			if tt.wantAttempt != 0 && attempts != tt.wantAttempt {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempt)
+			}
+			if gotType != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", gotType, tt.wantType)
+			}
+			if tt.wantBody != "" && string(gotBody) != tt.wantBody {
+				t.Errorf("body = %q, want %q", gotBody, tt.wantBody)
+			}
+			if tt.dest.Secret != "" && gotSig != "sha256="+sign(tt.dest.Secret, gotBody) {
+				t.Errorf("signature = %q does not match the body", gotSig)
+			}
+		})
+	}
+}
+
+func TestDestinationRetries(t *testing.T) {
+	two, negative := 2, -1
+	tests := []struct {
+		retries *int
+		want    int
+		wantErr bool
+	}{
+		{retries: nil, want: 3},
+		{retries: new(int), want: 0},
+		{retries: &two, want: 2},
+		{retries: &negative, wantErr: true},
+	}
+	for _, tt := range tests {
+		d := Destination{Type: "webhook", URL: "http://localhost", Retries: tt.retries}
+		err := d.validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validate error = %v, want error %v", err, tt.wantErr)
+			continue
+		}
+		if err == nil && d.retries() != tt.want {
+			t.Errorf("retries() = %d, want %d", d.retries(), tt.want)
+		}
+	}
+}